@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gostrecka/internal/utils/static"
+	"gostrecka/models"
+	"gostrecka/services/database"
+	"gostrecka/services/env"
+	"gostrecka/services/export"
+	"gostrecka/services/i18n"
+	"gostrecka/services/importer"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sarulabs/di/v2"
+)
+
+var exportFlag = flag.String("export", "", "Export transactions, users, products or sie and exit")
+var formatFlag = flag.String("format", export.FormatCsv, "Export format of transactions, users and products, csv or json")
+var fromFlag = flag.String("from", "", "First day of a transaction or SIE export as YYYY-MM-DD, defaults to the start of the month")
+var toFlag = flag.String("to", "", "Last day of a transaction or SIE export as YYYY-MM-DD, defaults to today")
+var outFlag = flag.String("out", "", "File to write the export to, defaults to stdout")
+var importFlag = flag.String("import", "", "Import products from a CSV file and exit, see -apply")
+var applyFlag = flag.Bool("apply", false, "Create the imported products instead of only reporting what would change")
+var stockUserFlag = flag.String("stock-user", "", "Discord user id credited for imported stock")
+
+// runExport writes the export selected by the flags.
+func runExport(ctn di.Container) (err error) {
+	db := ctn.Get(static.DiDatabase).(database.Database)
+
+	w := io.Writer(os.Stdout)
+	if *outFlag != "" {
+		file, err := os.Create(*outFlag)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		w = file
+	}
+
+	switch *exportFlag {
+	case "transactions":
+		from, to, err := cliPeriod()
+		if err != nil {
+			return err
+		}
+
+		transactions, err := db.GetTransactions(from, to)
+		if err != nil {
+			return err
+		}
+		return export.Transactions(w, *formatFlag, transactions)
+	case "sie":
+		from, to, err := cliPeriod()
+		if err != nil {
+			return err
+		}
+
+		entries, err := db.GetLedger(from, to)
+		if err != nil {
+			return err
+		}
+		return export.Sie(w, ctn.Get(static.DiConfig).(env.Config).Sie, from, to, entries)
+	case "users":
+		users, err := db.GetUsers()
+		if err != nil {
+			return err
+		}
+		return export.Users(w, *formatFlag, users)
+	case "products":
+		prices, err := db.GetPriceHistory()
+		if err != nil {
+			return err
+		}
+		return export.Products(w, *formatFlag, prices)
+	default:
+		return errors.New("-export must be transactions, users, products or sie")
+	}
+}
+
+// cliPeriod returns the period [from, to) given by -from and -to, which
+// defaults to the current month so far.
+func cliPeriod() (from time.Time, to time.Time, err error) {
+	now := time.Now()
+	from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	if *fromFlag != "" {
+		if from, err = time.ParseInLocation("2006-01-02", *fromFlag, time.Local); err != nil {
+			return from, to, fmt.Errorf("invalid -from: %w", err)
+		}
+	}
+	if *toFlag != "" {
+		if to, err = time.ParseInLocation("2006-01-02", *toFlag, time.Local); err != nil {
+			return from, to, fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// runImport imports products from the file given by -import and prints the
+// report.
+func runImport(ctn di.Container) error {
+	db := ctn.Get(static.DiDatabase).(database.Database)
+
+	file, err := os.Open(*importFlag)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	origin := models.Origin{Source: models.OriginCli, Reference: filepath.Base(*importFlag)}
+	report, err := importer.ImportProducts(db, origin, *stockUserFlag, file, *applyFlag)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(report.Format(i18n.Resolve(ctn.Get(static.DiConfig).(env.Config).Locale)))
+
+	if len(report.Errors) > 0 {
+		return errors.New("the file has errors, nothing was imported")
+	}
+
+	return nil
+}
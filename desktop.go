@@ -0,0 +1,136 @@
+//go:build !headless
+
+package main
+
+import (
+	"embed"
+	"gostrecka/internal/utils/static"
+	"gostrecka/services/achievements"
+	"gostrecka/services/audit"
+	"gostrecka/services/env"
+	"gostrecka/services/events"
+	"gostrecka/services/i18n"
+	"gostrecka/services/reports"
+	"gostrecka/services/transactions"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/sarulabs/di/v2"
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+//go:embed all:frontend/dist
+var Assets embed.FS
+
+// registerDesktop adds the kiosk application to the container.
+func registerDesktop(builder *di.EnhancedBuilder) {
+	builder.Add(&di.Def{
+		Name: "app",
+		Build: func(ctn di.Container) (interface{}, error) {
+			return createApplication(ctn), nil
+		},
+	})
+}
+
+// runDesktop opens the kiosk window and runs the Discord bot next to it
+// until the window is closed.
+func runDesktop(ctn di.Container) {
+	wailsApp := ctn.Get("app").(*application.App)
+	createMainWindow(wailsApp)
+	bridgeEvents(ctn.Get(static.DiEvents).(*events.Bus), wailsApp, i18n.Resolve(ctn.Get(static.DiConfig).(env.Config).Locale))
+
+	var wg sync.WaitGroup
+	discordReady := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		discord := ctn.Get("discord_service").(*DiscordService)
+		discord.Start()
+		close(discordReady)
+	}()
+
+	<-discordReady
+
+	wailsApp.Events.On("discord_check", func(ev *application.WailsEvent) {
+		discord := ctn.Get("discord_service").(*DiscordService)
+		if discord.session.State.Ready.SessionID != "" {
+			wailsApp.Events.Emit(&application.WailsEvent{Name: "discord_ready", Sender: "App", Data: map[string]interface{}{
+				"name":     discord.session.State.Ready.User.Username,
+				"icon_url": discord.session.State.Ready.User.AvatarURL("64x64"),
+			}})
+		}
+	})
+	err := wailsApp.Run()
+
+	if err != nil {
+		ctn.Get("logger").(*slog.Logger).Error("Failed to run application", "error", err)
+		os.Exit(1)
+	}
+
+	wg.Wait()
+}
+
+func createApplication(ctn di.Container) *application.App {
+	logger := ctn.Get("logger").(*slog.Logger)
+	return application.New(application.Options{
+		Name: "Jamkstrecka",
+		Assets: application.AssetOptions{
+			Handler:        application.AssetFileServerFS(Assets),
+			DisableLogging: true,
+		},
+		Logger: logger.With("service", "APP"),
+		Services: []application.Service{
+			application.NewService(transactions.New(ctn)),
+			application.NewService(audit.New(ctn)),
+			application.NewService(reports.New(ctn)),
+		},
+		OnShutdown: func() {
+			logger.Info("Shutting down application...")
+		},
+	})
+}
+
+func createMainWindow(app *application.App) {
+	app.NewWebviewWindowWithOptions(application.WebviewWindowOptions{
+		Name:             "Main Window",
+		Width:            1024,
+		Height:           768,
+		Title:            "Jamkstrecka",
+		URL:              "/",
+		BackgroundColour: application.NewRGB(27, 38, 54),
+		Mac: application.MacWindow{
+			InvisibleTitleBarHeight: 50,
+			Backdrop:                application.MacBackdropTranslucent,
+			TitleBar:                application.MacTitleBarHiddenInset,
+		},
+	})
+}
+
+// bridgeEvents forwards domain events that change what the kiosk shows
+// to the frontend, with messages in locale.
+func bridgeEvents(bus *events.Bus, app *application.App, locale i18n.Locale) {
+	bus.SubscribeAll("wails", func(event events.Event) {
+		switch e := event.(type) {
+		case events.TransactionCreated, events.TransactionReversed, events.StockAdded, events.PriceChanged:
+			app.Events.Emit(&application.WailsEvent{Name: "transaction_updated", Sender: "App"})
+		case events.BadgeUnlocked:
+			// The kiosk only shows the default database.
+			achievement, ok := achievements.Find(e.Badge)
+			if e.Guild != "" || !ok {
+				return
+			}
+			app.Events.Emit(&application.WailsEvent{Name: "badge_unlocked", Sender: "App", Data: map[string]interface{}{
+				"user_id": e.UserID,
+				"badge":   e.Badge,
+				"message": i18n.T(locale, "badge.kiosk", e.UserName, achievement.Emoji, achievement.Name(locale)),
+			}})
+		case events.DiscordReady:
+			app.Events.Emit(&application.WailsEvent{Name: "discord_ready", Sender: "Discord", Data: map[string]interface{}{
+				"name":     e.BotName,
+				"icon_url": e.IconURL,
+			}})
+		}
+	})
+}
@@ -0,0 +1,17 @@
+//go:build headless
+
+package main
+
+import (
+	"github.com/sarulabs/di/v2"
+)
+
+// registerDesktop is a no-op in headless builds, which do not link the
+// kiosk frontend.
+func registerDesktop(builder *di.EnhancedBuilder) {}
+
+// runDesktop falls back to running the bot only, as headless builds have
+// no kiosk window.
+func runDesktop(ctn di.Container) {
+	runHeadless(ctn)
+}
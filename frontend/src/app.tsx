@@ -1,4 +1,5 @@
-import { useCallback, useState } from "react";
+import { useCallback, useEffect, useState } from "react";
+import * as wails from "@wailsio/runtime";
 import DiscordStatus from "./components/discord-status";
 import { DrinkChart } from "./components/comptetitive-chart";
 import { Leaderboard } from "./components/leaderboard";
@@ -68,6 +69,19 @@ function App() {
 
   useKeyboardListener(onScan);
 
+  useEffect(() => {
+    wails.Events.On(
+      "badge_unlocked",
+      ({ data }: { data: { message: string } }) => {
+        addToast(data.message);
+      }
+    );
+
+    return () => {
+      wails.Events.Off("badge_unlocked");
+    };
+  }, []);
+
   return (
     <div className="flex-1 flex flex-col dark:bg-indigo-950 dark:text-white">
       <header className="p-4 flex flex-row items-center justify-between">
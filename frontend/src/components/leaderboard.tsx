@@ -30,21 +30,18 @@ export function Leaderboard() {
             <TableCell className="font-medium">{player.current_rank}</TableCell>
             <TableCell>{player.user_name}</TableCell>
             <TableCell className="text-right">
-              {player.total_transaction_count}
+              {player.score}
             </TableCell>
             <TableCell className="text-center">
-              {player.rank_change_indicator === "↑" && (
+              {player.previous_rank === 0 ? (
+                <Circle className="inline text-green-500 fill-green-500" />
+              ) : player.rank_change > 0 ? (
                 <ArrowUpIcon className="inline text-green-500" />
-              )}
-              {player.rank_change_indicator === "↓" && (
+              ) : player.rank_change < 0 ? (
                 <ArrowDownIcon className="inline text-red-500" />
-              )}
-              {player.rank_change_indicator === "=" && (
+              ) : (
                 <MinusIcon className="inline text-gray-500" />
               )}
-              {player.rank_change_indicator === "New" && (
-                <Circle className="inline text-green-500 fill-green-500" />
-              )}
             </TableCell>
           </TableRow>
         ))}
@@ -2,7 +2,10 @@ import { UserResponse } from "@/types";
 import { Card, CardHeader, CardTitle, CardContent } from "./ui/card";
 import { Badge } from "@/components/ui/badge";
 import { useCountdown } from "@/lib/utils";
-import { useEffect } from "react";
+import { useEffect, useState } from "react";
+
+/* @ts-ignore */
+import * as Service from "@/../bindings/gostrecka/services/transactions/transactionservice";
 
 type Props = {
   user: UserResponse | null;
@@ -11,6 +14,7 @@ type Props = {
 
 export const UserInfo = ({ user, onRemove }: Props) => {
   const [timeLeft, cancel, start] = useCountdown(() => onRemove());
+  const [swish, setSwish] = useState("");
 
   useEffect(() => {
     start(8);
@@ -20,6 +24,15 @@ export const UserInfo = ({ user, onRemove }: Props) => {
     };
   }, [user?.user.id]);
 
+  useEffect(() => {
+    setSwish("");
+    if (!user || user.balance.debt_incurred <= 0) {
+      return;
+    }
+
+    Service.GetSwishQR(user.user.id).then((qr: string) => setSwish(qr));
+  }, [user?.user.id, user?.balance.debt_incurred]);
+
   if (!user) {
     return null;
   }
@@ -69,6 +82,14 @@ export const UserInfo = ({ user, onRemove }: Props) => {
               </span>
             </p>
           </div>
+          {swish && (
+            <div className="flex flex-col items-center gap-2">
+              <img src={swish} alt="Swish" className="w-40 h-40 rounded bg-white p-2" />
+              <span className="text-sm text-slate-300">
+                Skanna med Swish för att betala
+              </span>
+            </div>
+          )}
         </div>
       </CardContent>
     </Card>
@@ -49,9 +49,12 @@ export type TransactionLeaderboard = {
 
   plater_rank: number;
   current_rank: number;
-  rank_change_indicator: "↑" | "↓" | "=" | "New";
+  previous_rank: number;
+  rank_change: number;
+  score: number;
   user_name: string;
   total_transaction_count: number;
+  total_spend: number;
 };
 
 export type Transaction = {
@@ -3,7 +3,16 @@ package static
 var (
 	DiDiscordSession = "discordgosession"
 	DiDatabase       = "database"
+	DiGuilds         = "guilds"
 	DiDesktop        = "desktop"
 	DiCommandHandler = "commandhandler"
 	DiConfig         = "config"
+	DiBackup         = "backup"
+	DiEvents         = "events"
+	DiApi            = "api"
+	DiMetrics        = "metrics"
+	DiReminders      = "reminders"
+	DiScheduler      = "scheduler"
+	DiLogChannel     = "logchannel"
+	DiAchievements   = "achievements"
 )
@@ -1,31 +1,41 @@
 package main
 
 import (
-	"embed"
+	"errors"
 	"flag"
 	"fmt"
+	"gostrecka/internal/utils/static"
+	"gostrecka/services/achievements"
+	"gostrecka/services/api"
+	"gostrecka/services/backup"
+	"gostrecka/services/database"
 	"gostrecka/services/database/sqlite"
+	"gostrecka/services/discord"
 	"gostrecka/services/discord/commands"
 	"gostrecka/services/env"
-	"gostrecka/services/transactions"
+	"gostrecka/services/events"
+	"gostrecka/services/logchannel"
+	"gostrecka/services/metrics"
+	"gostrecka/services/reminders"
+	"gostrecka/services/scheduler"
 	"gostrecka/utils"
 	"log/slog"
 	"os"
+	"os/signal"
 	"runtime"
-	"sync"
+	"syscall"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/lmittmann/tint"
 	"github.com/sarulabs/di/v2"
-	"github.com/wailsapp/wails/v3/pkg/application"
 	"github.com/zekrotja/ken"
 )
 
 var nFlag = flag.Bool("v", false, "Version")
-
-//go:embed all:frontend/dist
-var Assets embed.FS
+var restoreFlag = flag.String("restore", "", "Restore the database from a backup snapshot and exit")
+var restoreGuildFlag = flag.String("restore-guild", "", "Guild whose database -restore replaces, defaults to the default database")
+var headlessFlag = flag.Bool("headless", false, "Run the Discord bot without the kiosk window")
 
 func main() {
 	flag.Parse()
@@ -65,24 +75,77 @@ func main() {
 		},
 	})
 
+	builder.Add(&di.Def{
+		Name: static.DiEvents,
+		Build: func(ctn di.Container) (interface{}, error) {
+			logger := ctn.Get("logger").(*slog.Logger)
+			return events.NewBus(logger.With("service", "EVENTS")), nil
+		},
+	})
+
+	builder.Add(&di.Def{
+		Name: static.DiMetrics,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return metrics.New(ctn), nil
+		},
+		Close: func(obj interface{}) error {
+			return obj.(*metrics.MetricsService).Close()
+		},
+	})
+
 	builder.Add(&di.Def{
 		Name: "database",
 		Build: func(ctn di.Container) (interface{}, error) {
-			db := sqlite.New(ctn)
-			err := db.Connect()
+			return openDatabase(ctn, "", ctn.Get("config").(env.Config).DbUrl)
+		},
+		Close: func(obj interface{}) error {
+			// If your Database needs any cleanup, do it here
+			return nil
+		},
+	})
 
-			if err != nil {
-				return nil, err
+	builder.Add(&di.Def{
+		Name: static.DiGuilds,
+		Build: func(ctn di.Container) (interface{}, error) {
+			guilds := database.NewGuilds(ctn.Get(static.DiDatabase).(database.Database))
+			for _, guild := range ctn.Get("config").(env.Config).Guilds {
+				if guild.DbUrl == "" {
+					continue
+				}
+
+				db, err := openDatabase(ctn, guild.ID, guild.DbUrl)
+				if err != nil {
+					guilds.Close()
+					return nil, fmt.Errorf("could not open database of guild %s: %w", guild.ID, err)
+				}
+				guilds.Add(guild.ID, db)
 			}
 
-			return db, nil
+			return guilds, nil
 		},
 		Close: func(obj interface{}) error {
-			// If your Database needs any cleanup, do it here
+			obj.(*database.Guilds).Close()
 			return nil
 		},
 	})
 
+	builder.Add(&di.Def{
+		Name: static.DiBackup,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return backup.New(ctn), nil
+		},
+	})
+
+	builder.Add(&di.Def{
+		Name: static.DiApi,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return api.New(ctn), nil
+		},
+		Close: func(obj interface{}) error {
+			return obj.(*api.ApiService).Close()
+		},
+	})
+
 	builder.Add(&di.Def{Name: "discord_service",
 		Build: func(ctn di.Container) (interface{}, error) {
 			return NewDiscordService(ctn), nil
@@ -94,82 +157,143 @@ func main() {
 	})
 
 	builder.Add(&di.Def{
-		Name: "app",
+		Name: static.DiDiscordSession,
+		Build: func(ctn di.Container) (interface{}, error) {
+			service := ctn.Get("discord_service").(*DiscordService)
+			if service == nil {
+				return nil, errors.New("discord service is not available")
+			}
+			return service.session, nil
+		},
+	})
+
+	builder.Add(&di.Def{
+		Name: static.DiReminders,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return reminders.New(ctn), nil
+		},
+	})
+
+	builder.Add(&di.Def{
+		Name: static.DiLogChannel,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return logchannel.New(ctn), nil
+		},
+	})
+
+	builder.Add(&di.Def{
+		Name: static.DiAchievements,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return achievements.New(ctn), nil
+		},
+	})
+
+	builder.Add(&di.Def{
+		Name: static.DiScheduler,
 		Build: func(ctn di.Container) (interface{}, error) {
-			return createApplication(ctn), nil
+			s := scheduler.New(ctn)
+
+			s.Register(scheduler.Job{
+				Name:        "backup",
+				Description: "Säkerhetskopierar databaserna",
+				Run: func() error {
+					_, err := ctn.Get(static.DiBackup).(*backup.BackupService).SnapshotAll()
+					return err
+				},
+			})
+			s.Register(scheduler.Job{
+				Name:        "reminders",
+				Description: "Påminner användare om skulder",
+				Run: func() error {
+					_, err := ctn.Get(static.DiReminders).(*reminders.ReminderService).Run()
+					return err
+				},
+			})
+
+			return s, nil
 		},
 	})
 
+	registerDesktop(builder)
+
 	ctn, _ := builder.Build()
 	defer ctn.DeleteWithSubContainers()
 
-	var wg sync.WaitGroup
-	discordReady := make(chan struct{})
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		discord := ctn.Get("discord_service").(*DiscordService)
-		discord.Start()
-		close(discordReady)
-	}()
-
-	wailsApp := ctn.Get("app").(*application.App)
-	createMainWindow(wailsApp)
-
-	<-discordReady
-
-	wailsApp.Events.On("discord_check", func(ev *application.WailsEvent) {
-		discord := ctn.Get("discord_service").(*DiscordService)
-		if discord.session.State.Ready.SessionID != "" {
-			wailsApp.Events.Emit(&application.WailsEvent{Name: "discord_ready", Sender: "App", Data: map[string]interface{}{
-				"name":     discord.session.State.Ready.User.Username,
-				"icon_url": discord.session.State.Ready.User.AvatarURL("64x64"),
-			}})
+	if *restoreFlag != "" {
+		logger := ctn.Get("logger").(*slog.Logger)
+		config := ctn.Get("config").(env.Config)
+		dbUrl, err := config.DbUrlOf(*restoreGuildFlag)
+		if err != nil {
+			logger.Error("Failed to restore database", "error", err)
+			os.Exit(1)
 		}
-	})
-	err = wailsApp.Run()
+		if err := backup.Restore(dbUrl, *restoreFlag, logger.With("service", "BACKUP")); err != nil {
+			logger.Error("Failed to restore database", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	if err != nil {
-		ctn.Get("logger").(*slog.Logger).Error("Failed to run application", "error", err)
-		os.Exit(1)
+	if *exportFlag != "" {
+		if err := runExport(ctn); err != nil {
+			ctn.Get("logger").(*slog.Logger).Error("Failed to export", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *importFlag != "" {
+		if err := runImport(ctn); err != nil {
+			ctn.Get("logger").(*slog.Logger).Error("Failed to import", "error", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	wg.Wait()
+	ctn.Get(static.DiAchievements).(*achievements.AchievementService).Start()
+	go ctn.Get(static.DiApi).(*api.ApiService).Start()
+	go ctn.Get(static.DiMetrics).(*metrics.MetricsService).Start()
+	go ctn.Get(static.DiScheduler).(*scheduler.SchedulerService).Start()
+	go ctn.Get(static.DiLogChannel).(*logchannel.LogChannelService).Start()
+
+	if *headlessFlag || ctn.Get("config").(env.Config).Headless {
+		runHeadless(ctn)
+		return
+	}
+
+	runDesktop(ctn)
 }
 
-func createApplication(ctn di.Container) *application.App {
-	logger := ctn.Get("logger").(*slog.Logger)
-	return application.New(application.Options{
-		Name: "Jamkstrecka",
-		Assets: application.AssetOptions{
-			Handler:        application.AssetFileServerFS(Assets),
-			DisableLogging: true,
-		},
-		Logger: logger.With("service", "APP"),
-		Services: []application.Service{
-			application.NewService(transactions.New(ctn)),
-		},
-		OnShutdown: func() {
-			logger.Info("Shutting down application...")
-		},
-	})
+// openDatabase connects to the database of guild at path and migrates it.
+func openDatabase(ctn di.Container, guild string, path string) (database.Database, error) {
+	db := sqlite.New(ctn)
+	db.Path = path
+	db.Guild = guild
+	if err := db.Connect(); err != nil {
+		return nil, err
+	}
+
+	if m := ctn.Get(static.DiMetrics).(*metrics.MetricsService); m.Enabled() {
+		return m.Instrument(db), nil
+	}
+
+	return db, nil
 }
 
-func createMainWindow(app *application.App) {
-	app.NewWebviewWindowWithOptions(application.WebviewWindowOptions{
-		Name:             "Main Window",
-		Width:            1024,
-		Height:           768,
-		Title:            "Jamkstrecka",
-		URL:              "/",
-		BackgroundColour: application.NewRGB(27, 38, 54),
-		Mac: application.MacWindow{
-			InvisibleTitleBarHeight: 50,
-			Backdrop:                application.MacBackdropTranslucent,
-			TitleBar:                application.MacTitleBarHiddenInset,
-		},
-	})
+// runHeadless runs the Discord bot and the database without the kiosk
+// window until the process is interrupted.
+func runHeadless(ctn di.Container) {
+	logger := ctn.Get("logger").(*slog.Logger)
+	logger.Info("Running headless, the kiosk window is disabled")
+
+	discord := ctn.Get("discord_service").(*DiscordService)
+	discord.Start()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	logger.Info("Shutting down...")
 }
 
 type ContainerAdapter struct {
@@ -207,44 +331,110 @@ func NewDiscordService(ctn di.Container) *DiscordService {
 }
 
 func (d *DiscordService) Start() {
+	guilds := d.container.Get("config").(env.Config).GuildIDs()
+
+	// ken registers the commands in the first guild, or globally when there
+	// are none, and they are registered in the rest once connected.
+	localizer := &discord.CommandLocalizer{Session: d.session}
+	if len(guilds) > 0 {
+		localizer.Guild = guilds[0]
+	}
+
 	k, err := ken.New(d.session, ken.Options{
 		DependencyProvider: &ContainerAdapter{container: d.container},
+		CommandStore:       localizer,
 	})
 	if err != nil {
 		d.logger.Error("Failed to create ken", "error", err)
 		return
 	}
 
-	err = k.RegisterCommands(
+	cmds := []ken.Command{
 		new(commands.HelpCommand),
 		new(commands.UserCommand),
 		new(commands.StreckaCommand),
 		new(commands.ProductCommand),
 		new(commands.BalanceCommand),
 		new(commands.PrintCommand),
-	)
+		new(commands.BackupCommand),
+		new(commands.AuditCommand),
+		new(commands.ReverseCommand),
+		new(commands.PaymentCommand),
+		new(commands.StatementCommand),
+		new(commands.SettingsCommand),
+		new(commands.JobsCommand),
+		new(commands.ExportCommand),
+		new(commands.ImportCommand),
+		new(commands.ReportCommand),
+		new(commands.LeaderboardCommand),
+		new(commands.HistoryCommand),
+		new(commands.BadgesCommand),
+		new(commands.StreckaUserCommand),
+		new(commands.StreckaMessageCommand),
+		new(commands.BalanceUserCommand),
+		new(commands.HistoryUserCommand),
+	}
+	discord.ScopeCommands(localizer.Guild, cmds...)
 
-	if err != nil {
+	if err = k.RegisterCommands(cmds...); err != nil {
 		d.logger.Error("Failed to register commands", "error", err)
 		return
 	}
 
+	if err = k.RegisterMiddlewares(new(discord.AdminMiddleware)); err != nil {
+		d.logger.Error("Failed to register middlewares", "error", err)
+		return
+	}
+
+	if m := d.container.Get(static.DiMetrics).(*metrics.MetricsService); m.Enabled() {
+		m.WatchSession(d.session)
+		if err = k.RegisterMiddlewares(m.CommandMiddleware()); err != nil {
+			d.logger.Error("Failed to register middlewares", "error", err)
+			return
+		}
+	}
+
 	err = d.session.Open()
 	if err != nil {
 		d.logger.Error("Failed to open discord session", "error", err)
 		return
 	}
 
+	d.registerCommands(k, guilds)
+
 	d.logger.Info("Discord service started", "bot_name", d.session.State.Ready.User.Username)
-	wails := d.container.Get("app").(*application.App)
-	wails.Events.Emit(&application.WailsEvent{Name: "discord_ready", Sender: "Discord", Data: map[string]interface{}{
-		"name":     d.session.State.Ready.User.Username,
-		"icon_url": d.session.State.Ready.User.AvatarURL("64x64"),
-	}})
+	bus := d.container.Get(static.DiEvents).(*events.Bus)
+	bus.Publish(events.DiscordReady{
+		BotName: d.session.State.Ready.User.Username,
+		IconURL: d.session.State.Ready.User.AvatarURL("64x64"),
+	})
+}
+
+// registerCommands registers the commands in every guild but the first,
+// where ken does, and removes the ones registered globally before the
+// guilds were configured, which would otherwise be shown twice.
+func (d *DiscordService) registerCommands(k *ken.Ken, guilds []string) {
+	if len(guilds) == 0 {
+		return
+	}
+
+	for _, guild := range guilds[1:] {
+		if err := discord.RegisterCommands(d.session, guild, k.GetCommandInfo()); err != nil {
+			d.logger.Error("Failed to register commands", "guild", guild, "error", err)
+		}
+	}
+
+	if _, err := d.session.ApplicationCommandBulkOverwrite(d.session.State.User.ID, "", []*discordgo.ApplicationCommand{}); err != nil {
+		d.logger.Error("Failed to remove global commands", "error", err)
+	}
 }
 
 func NewLogger() *slog.Logger {
 	w := os.Stdout
+	if *exportFlag != "" {
+		// Exports may be written to stdout, keep the logs out of them.
+		w = os.Stderr
+	}
 
 	opts := &tint.Options{
 		Level:      slog.LevelDebug,
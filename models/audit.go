@@ -0,0 +1,60 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	OriginDiscord = "discord"
+	OriginKiosk   = "kiosk"
+	OriginCli     = "cli"
+	OriginApi     = "api"
+	OriginSystem  = "system"
+)
+
+// OriginSources are the sources a mutation can be made from. The audit log
+// only accepts these, so a new source is added here and nowhere else.
+var OriginSources = []string{OriginDiscord, OriginKiosk, OriginCli, OriginApi, OriginSystem}
+
+// Origin describes who performed a mutation and from where.
+type Origin struct {
+	Source    string `json:"source"`
+	ActorID   string `json:"actor_id"`
+	Reference string `json:"reference"`
+}
+
+// Validate returns an error if the origin has an unknown source.
+func (o Origin) Validate() error {
+	if !slices.Contains(OriginSources, o.Source) {
+		return fmt.Errorf("unknown origin source %q", o.Source)
+	}
+
+	return nil
+}
+
+type AuditEntry struct {
+	ID           int64     `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	ActorID      string    `json:"actor_id"`
+	TargetUserID string    `json:"target_user_id"`
+	Source       string    `json:"source"`
+	Reference    string    `json:"reference"`
+	Action       string    `json:"action"`
+	EntityType   string    `json:"entity_type"`
+	EntityID     string    `json:"entity_id"`
+	Before       string    `json:"before"`
+	After        string    `json:"after"`
+}
+
+// AuditFilter narrows down GetAuditLog. Zero values are ignored.
+type AuditFilter struct {
+	ActorID      string    `json:"actor_id"`
+	TargetUserID string    `json:"target_user_id"`
+	Source       string    `json:"source"`
+	Action       string    `json:"action"`
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	Limit        int       `json:"limit"`
+}
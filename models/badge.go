@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Badge is an achievement a user has unlocked.
+type Badge struct {
+	UserID     string    `json:"user_id"`
+	Badge      string    `json:"badge"`
+	UnlockedAt time.Time `json:"unlocked_at"`
+}
+
+// BadgeStats are the figures of a user that achievements are unlocked by.
+type BadgeStats struct {
+	// Transactions is the number of times the user has streckat.
+	Transactions int64 `json:"transactions"`
+	// Units is how many items the user has streckat in total.
+	Units int64 `json:"units"`
+	// ProductsTried is the number of different products the user has
+	// streckat, out of Products.
+	ProductsTried int64 `json:"products_tried"`
+	Products      int64 `json:"products"`
+	// StockAdded is how many items of stock the user has added, and
+	// MostStockAdded the most any user has.
+	StockAdded     int64 `json:"stock_added"`
+	MostStockAdded int64 `json:"most_stock_added"`
+}
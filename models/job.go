@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+const (
+	JobRunning = "running"
+	JobOk      = "ok"
+	JobFailed  = "error"
+)
+
+// JobRun is the latest run of a scheduled job. FinishedAt is zero while the
+// job is running.
+type JobRun struct {
+	Name       string    `json:"name"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error"`
+}
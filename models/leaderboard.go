@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	LeaderboardTonight = "tonight"
+	LeaderboardWeek    = "week"
+	LeaderboardTerm    = "term"
+	LeaderboardAllTime = "all"
+)
+
+const (
+	LeaderboardUnits = "units"
+	LeaderboardSpend = "spend"
+)
+
+// LeaderboardQuery describes a leaderboard. Units and spend can be limited
+// to a single product or category.
+type LeaderboardQuery struct {
+	Window    string `json:"window"`
+	Metric    string `json:"metric"`
+	ProductID int64  `json:"product_id"`
+	Category  string `json:"category"`
+}
+
+// LeaderboardFilter is a leaderboard query with its window resolved. From
+// is zero for all time, and previous ranks count what was bought before
+// Compare.
+type LeaderboardFilter struct {
+	From      time.Time
+	Compare   time.Time
+	Metric    string
+	ProductID int64
+	Category  string
+}
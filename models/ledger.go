@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const (
+	LedgerInternalSale = "internal_sale"
+	LedgerExternalSale = "external_sale"
+	LedgerStock        = "stock"
+	LedgerPayment      = "payment"
+	LedgerWriteOff     = "write_off"
+)
+
+// PaymentTypes are the kinds of user payments, a write-off settles debt
+// that will never be paid.
+var PaymentTypes = []string{LedgerPayment, LedgerWriteOff}
+
+// LedgerEntry is the total of one kind of bookkeeping event on a day.
+type LedgerEntry struct {
+	Date   time.Time `json:"date"`
+	Kind   string    `json:"kind"`
+	Amount float64   `json:"amount"`
+}
@@ -6,6 +6,10 @@ type Product struct {
 	ID         int64  `json:"id"`
 	Name       string `json:"name"`
 	TotalStock int    `json:"total_stock"`
+	Category   string `json:"category"`
+	// Upc is the barcode the product is scanned with, only set when the
+	// product is looked up by id.
+	Upc string `json:"upc"`
 }
 
 type ProductPrice struct {
@@ -19,6 +23,15 @@ type ProductPrice struct {
 }
 
 type ProductWithPrice struct {
-	Product Product
-	Price   ProductPrice
+	Product Product      `json:"product"`
+	Price   ProductPrice `json:"price"`
+}
+
+// NewProduct is a product to create together with its first stock.
+type NewProduct struct {
+	Name          string
+	PurchasePrice float64
+	InternalPrice float64
+	ExternalPrice float64
+	Stock         int64
 }
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ProductSales is what was sold of one product on one day. Cost is the
+// purchase price of the units sold at the time they were sold.
+type ProductSales struct {
+	Date        time.Time `json:"date"`
+	ProductID   int64     `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	Category    string    `json:"category"`
+	Quantity    int64     `json:"quantity"`
+	Revenue     float64   `json:"revenue"`
+	Cost        float64   `json:"cost"`
+}
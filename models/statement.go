@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	StatementStrecka = "strecka"
+	StatementStock   = "stock"
+	StatementPayment = "payment"
+)
+
+// StatementEntry is a single row on a user's statement. Amount is positive
+// when it is credited to the user and negative when it is charged.
+type StatementEntry struct {
+	Date        time.Time `json:"date"`
+	Kind        string    `json:"kind"`
+	Description string    `json:"description"`
+	Quantity    int64     `json:"quantity"`
+	UnitPrice   float64   `json:"unit_price"`
+	Amount      float64   `json:"amount"`
+}
+
+// Statement lists everything affecting a user's balance during a period.
+// Balances are positive for credit and negative for debt.
+type Statement struct {
+	User           User             `json:"user"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	OpeningBalance float64          `json:"opening_balance"`
+	Entries        []StatementEntry `json:"entries"`
+	ClosingBalance float64          `json:"closing_balance"`
+}
@@ -14,12 +14,44 @@ type LatestTransaction struct {
 	UserName                   string    `json:"user_name"`
 	TransactionDate            time.Time `json:"transaction_date"`
 	CumulativeTransactionCount int64     `json:"cumulative_transaction_count"`
+	CumulativeSpend            float64   `json:"cumulative_spend"`
 }
 
 type TransactionLeaderboard struct {
-	UserID                string `json:"user_id"`
-	UserName              string `json:"user_name"`
-	CurrentRank           int64  `json:"current_rank"`
-	RankChangeIndicator   string `json:"rank_change_indicator"`
-	TotalTransactionCount int64  `json:"total_transaction_count"`
+	UserID      string `json:"user_id"`
+	UserName    string `json:"user_name"`
+	CurrentRank int64  `json:"current_rank"`
+	// PreviousRank is the rank before the comparison time, zero when the
+	// user was not on the leaderboard then.
+	PreviousRank int64 `json:"previous_rank"`
+	// RankChange is how many places the user has climbed since the
+	// comparison time, negative when they have dropped.
+	RankChange            int64   `json:"rank_change"`
+	Score                 float64 `json:"score"`
+	TotalTransactionCount int64   `json:"total_transaction_count"`
+	TotalSpend            float64 `json:"total_spend"`
+}
+
+// TransactionRecord is a single strecka with the user and product it
+// concerns, as used for exports.
+type TransactionRecord struct {
+	ID              int64     `json:"id"`
+	TransactionDate time.Time `json:"transaction_date"`
+	UserID          string    `json:"user_id"`
+	UserName        string    `json:"user_name"`
+	ProductID       int64     `json:"product_id"`
+	ProductName     string    `json:"product_name"`
+	Quantity        int64     `json:"quantity"`
+	PriceType       string    `json:"price_type"`
+	PricePaid       float64   `json:"price_paid"`
+}
+
+// TransactionFilter selects a page of one user's transactions, newest
+// first. Zero times leave the period open.
+type TransactionFilter struct {
+	UserID string    `json:"user_id"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Limit  int       `json:"limit"`
+	Offset int       `json:"offset"`
 }
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type User struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -12,3 +14,18 @@ type Balance struct {
 	RemainingCredits   float64 `json:"remaining_credits"`
 	DebtIncurred       float64 `json:"debt_incurred"`
 }
+
+type UserWithBalance struct {
+	User    User    `json:"user"`
+	Balance Balance `json:"balance"`
+}
+
+// UserSettings holds a user's preferences. LastRemindedAt is zero when the
+// user has never been sent a debt reminder, Locale is empty when the user
+// follows the language of their Discord client.
+type UserSettings struct {
+	UserID         string    `json:"user_id"`
+	DebtReminders  bool      `json:"debt_reminders"`
+	Locale         string    `json:"locale"`
+	LastRemindedAt time.Time `json:"last_reminded_at"`
+}
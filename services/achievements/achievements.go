@@ -0,0 +1,221 @@
+package achievements
+
+import (
+	"gostrecka/internal/utils/static"
+	"gostrecka/models"
+	"gostrecka/services/database"
+	"gostrecka/services/env"
+	"gostrecka/services/events"
+	"gostrecka/services/i18n"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/sarulabs/di/v2"
+)
+
+// nightOwlUntil is the hour of the night before which a strecka unlocks
+// the night owl badge.
+const nightOwlUntil = 5
+
+// Achievement is a badge users unlock by what they strecka and restock.
+// Its name and description are in the catalogue as "badge." and the ID,
+// and the same followed by "_description".
+type Achievement struct {
+	ID    string
+	Emoji string
+	// Unlocked reports whether the user has earned the achievement, given
+	// their stats after the change in event.
+	Unlocked func(event events.Event, stats models.BadgeStats) bool
+}
+
+// Achievements are all achievements, in the order they are listed in.
+var Achievements = []Achievement{
+	{
+		ID:    "first_strecka",
+		Emoji: "✏️",
+		Unlocked: func(_ events.Event, stats models.BadgeStats) bool {
+			return stats.Transactions > 0
+		},
+	},
+	{
+		ID:    "hundred_cans",
+		Emoji: "🥫",
+		Unlocked: func(_ events.Event, stats models.BadgeStats) bool {
+			return stats.Units >= 100
+		},
+	},
+	{
+		ID:    "every_product",
+		Emoji: "🧭",
+		Unlocked: func(_ events.Event, stats models.BadgeStats) bool {
+			return stats.Products > 0 && stats.ProductsTried >= stats.Products
+		},
+	},
+	{
+		ID:    "night_owl",
+		Emoji: "🦉",
+		Unlocked: func(event events.Event, _ models.BadgeStats) bool {
+			transaction, ok := event.(events.TransactionCreated)
+			return ok && transaction.CreatedAt.Hour() < nightOwlUntil
+		},
+	},
+	{
+		ID:    "top_restocker",
+		Emoji: "📦",
+		Unlocked: func(_ events.Event, stats models.BadgeStats) bool {
+			return stats.StockAdded > 0 && stats.StockAdded >= stats.MostStockAdded
+		},
+	},
+}
+
+// Find returns the achievement with the id.
+func Find(id string) (achievement Achievement, ok bool) {
+	for _, achievement := range Achievements {
+		if achievement.ID == id {
+			return achievement, true
+		}
+	}
+
+	return
+}
+
+// Name returns the name of the achievement in locale.
+func (a Achievement) Name(locale i18n.Locale) string {
+	return i18n.T(locale, "badge."+a.ID)
+}
+
+// Description returns how the achievement is unlocked in locale.
+func (a Achievement) Description(locale i18n.Locale) string {
+	return i18n.T(locale, "badge."+a.ID+"_description")
+}
+
+// Evaluate unlocks the achievements the user has earned with the change in
+// event and returns the ones they did not have before.
+func Evaluate(db database.Database, event events.Event, userId string, at time.Time) (unlocked []Achievement, err error) {
+	stats, err := db.GetBadgeStats(userId)
+	if err != nil {
+		return
+	}
+
+	badges, err := db.GetBadges(userId)
+	if err != nil {
+		return
+	}
+
+	had := make(map[string]bool, len(badges))
+	for _, badge := range badges {
+		had[badge.Badge] = true
+	}
+
+	for _, achievement := range Achievements {
+		if had[achievement.ID] || !achievement.Unlocked(event, stats) {
+			continue
+		}
+
+		added, err := db.UnlockBadge(userId, achievement.ID, at)
+		if err != nil {
+			return unlocked, err
+		}
+		if added {
+			unlocked = append(unlocked, achievement)
+		}
+	}
+
+	return unlocked, nil
+}
+
+type AchievementService struct {
+	container di.Container
+	logger    *slog.Logger
+	config    env.Config
+	locale    i18n.Locale
+}
+
+func New(container di.Container) *AchievementService {
+	config := container.Get(static.DiConfig).(env.Config)
+
+	return &AchievementService{
+		container: container,
+		logger:    container.Get("logger").(*slog.Logger).With("service", "ACHIEVEMENTS"),
+		config:    config,
+		locale:    i18n.Resolve(config.Locale),
+	}
+}
+
+// Start evaluates the achievements of the user every strecka and stock
+// addition is for, and announces the badges they unlock.
+func (s *AchievementService) Start() {
+	bus := s.container.Get(static.DiEvents).(*events.Bus)
+	bus.SubscribeAll("achievements", s.onEvent)
+}
+
+func (s *AchievementService) onEvent(event events.Event) {
+	var guild, userId string
+	var at time.Time
+
+	switch e := event.(type) {
+	case events.TransactionCreated:
+		guild, userId, at = e.Guild, e.UserID, e.CreatedAt
+	case events.StockAdded:
+		guild, userId, at = e.Guild, e.UserID, e.CreatedAt
+	default:
+		return
+	}
+
+	if userId == "" {
+		return
+	}
+
+	db := s.container.Get(static.DiGuilds).(*database.Guilds).Get(guild)
+	unlocked, err := Evaluate(db, event, userId, at)
+	if err != nil {
+		s.logger.Error("Could not evaluate achievements", "user", userId, "error", err)
+	}
+	if len(unlocked) == 0 {
+		return
+	}
+
+	user, _, err := db.GetUser(userId)
+	if err != nil {
+		s.logger.Warn("Could not get user with new badges", "user", userId, "error", err)
+		user.ID = userId
+	}
+
+	bus := s.container.Get(static.DiEvents).(*events.Bus)
+	for _, achievement := range unlocked {
+		s.logger.Info("Badge unlocked", "user", userId, "badge", achievement.ID)
+		s.announce(guild, user, achievement)
+
+		bus.Publish(events.BadgeUnlocked{
+			UserID:     user.ID,
+			UserName:   user.Name,
+			Badge:      achievement.ID,
+			Guild:      guild,
+			UnlockedAt: at,
+		})
+	}
+}
+
+// announce posts the unlocked badge to the badge channel of the guild.
+func (s *AchievementService) announce(guild string, user models.User, achievement Achievement) {
+	channel := s.config.BadgeChannel(guild)
+	if channel == "" {
+		return
+	}
+
+	session, err := s.container.SafeGet(static.DiDiscordSession)
+	if err != nil {
+		s.logger.Warn("no Discord session, not announcing badge", "error", err)
+		return
+	}
+
+	_, err = session.(*discordgo.Session).ChannelMessageSendEmbed(channel, &discordgo.MessageEmbed{
+		Title: i18n.T(s.locale, "badge.announcement"),
+		Description: i18n.T(s.locale, "badge.unlocked", "<@"+user.ID+">", achievement.Emoji,
+			achievement.Name(s.locale), achievement.Description(s.locale)),
+	})
+	if err != nil {
+		s.logger.Error("Could not announce badge", "channel", channel, "error", err)
+	}
+}
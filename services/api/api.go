@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gostrecka/internal/utils/static"
+	"gostrecka/services/database"
+	"gostrecka/services/env"
+	"gostrecka/services/events"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/sarulabs/di/v2"
+)
+
+type ApiService struct {
+	container di.Container
+	logger    *slog.Logger
+	config    env.ApiConfig
+	server    *http.Server
+	mux       *http.ServeMux
+	feed      *feed
+	closing   chan struct{}
+}
+
+func New(container di.Container) *ApiService {
+	config := container.Get(static.DiConfig).(env.Config).Api
+
+	s := &ApiService{
+		container: container,
+		logger:    container.Get("logger").(*slog.Logger).With("service", "API"),
+		config:    config,
+		mux:       http.NewServeMux(),
+		closing:   make(chan struct{}),
+	}
+
+	s.feed = newFeed(s)
+	if config.Enabled {
+		s.feed.listen(container.Get(static.DiEvents).(*events.Bus))
+	}
+
+	s.routes()
+	s.server = &http.Server{
+		Addr:              config.Listen,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// Shutdown does not interrupt active streams on its own.
+	s.server.RegisterOnShutdown(func() { close(s.closing) })
+
+	return s
+}
+
+func (s *ApiService) routes() {
+	s.mux.HandleFunc("GET /api/users", s.requireScope(ScopeUsers, s.getUsers))
+	s.mux.HandleFunc("GET /api/users/{id}", s.requireScope(ScopeUsers, s.getUser))
+	s.mux.HandleFunc("GET /api/products", s.requireScope(ScopeProducts, s.getProducts))
+	s.mux.HandleFunc("GET /api/products/{id}", s.requireScope(ScopeProducts, s.getProduct))
+	s.mux.HandleFunc("GET /api/upc/{upc}", s.requireScope(ScopeProducts, s.getUpc))
+	s.mux.HandleFunc("GET /api/leaderboard", s.requireScope(ScopeLeaderboard, s.getLeaderboard))
+	s.mux.HandleFunc("GET /api/transactions/latest", s.requireScope(ScopeLeaderboard, s.getLatestTransactions))
+	s.mux.HandleFunc("POST /api/strecka", s.requireScope(ScopeStrecka, s.postStrecka))
+	s.mux.HandleFunc("GET /api/stream", s.requireScope(ScopeStream, s.getStream))
+}
+
+// Handle registers an additional handler on the API server. It must be
+// called before Start.
+func (s *ApiService) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Enabled reports whether the API is turned on in the config.
+func (s *ApiService) Enabled() bool {
+	return s.config.Enabled
+}
+
+// Start serves the API until Close is called. It returns immediately when
+// the API is disabled.
+func (s *ApiService) Start() {
+	if !s.config.Enabled {
+		return
+	}
+
+	if len(s.config.Tokens) == 0 {
+		s.logger.Warn("API enabled without any tokens, all requests will be rejected")
+	}
+
+	s.logger.Info("API listening", "address", s.config.Listen)
+	err := s.server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.logger.Error("API server stopped", "error", err)
+	}
+}
+
+func (s *ApiService) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return s.server.Shutdown(ctx)
+}
+
+func (s *ApiService) db() database.Database {
+	return s.container.Get(static.DiDatabase).(database.Database)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"gostrecka/services/env"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	ScopeUsers       = "users:read"
+	ScopeProducts    = "products:read"
+	ScopeLeaderboard = "leaderboard:read"
+	ScopeStrecka     = "strecka:write"
+	ScopeStream      = "stream:read"
+
+	// ScopeAll grants every scope.
+	ScopeAll = "*"
+)
+
+type tokenKey struct{}
+
+// requireScope wraps handler so it is only called for requests carrying a
+// bearer token that has the given scope.
+func (s *ApiService) requireScope(scope string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := s.authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="jamkstrecka"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+
+		if !slices.Contains(token.Scopes, scope) && !slices.Contains(token.Scopes, ScopeAll) {
+			writeError(w, http.StatusForbidden, "token lacks scope "+scope)
+			return
+		}
+
+		handler(w, r.WithContext(context.WithValue(r.Context(), tokenKey{}, token)))
+	}
+}
+
+func (s *ApiService) authenticate(r *http.Request) (env.ApiToken, bool) {
+	header := r.Header.Get("Authorization")
+	secret, _ := strings.CutPrefix(header, "Bearer ")
+
+	// Browsers cannot set headers on EventSource connections, so read-only
+	// requests may pass the token as a query parameter instead.
+	if secret == "" && r.Method == http.MethodGet {
+		secret = r.URL.Query().Get("access_token")
+	}
+
+	if secret == "" {
+		return env.ApiToken{}, false
+	}
+
+	for _, token := range s.config.Tokens {
+		if token.Token != "" && subtle.ConstantTimeCompare([]byte(token.Token), []byte(secret)) == 1 {
+			return token, true
+		}
+	}
+
+	return env.ApiToken{}, false
+}
+
+// requestToken returns the token the request was authenticated with.
+func requestToken(r *http.Request) env.ApiToken {
+	token, _ := r.Context().Value(tokenKey{}).(env.ApiToken)
+	return token
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"gostrecka/internal/utils/static"
+	"gostrecka/models"
+	"gostrecka/services/env"
+	"gostrecka/services/leaderboard"
+	"net/http"
+	"strconv"
+)
+
+func (s *ApiService) getUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := s.db().GetUsers()
+	if err != nil {
+		s.logger.Error("could not list users", "error", err)
+		writeError(w, http.StatusInternalServerError, "could not list users")
+		return
+	}
+
+	if users == nil {
+		users = []models.UserWithBalance{}
+	}
+
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (s *ApiService) getUser(w http.ResponseWriter, r *http.Request) {
+	user, balance, err := s.db().GetUser(r.PathValue("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	if err != nil {
+		s.logger.Error("could not get user", "error", err)
+		writeError(w, http.StatusInternalServerError, "could not get user")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.UserWithBalance{User: user, Balance: balance})
+}
+
+func (s *ApiService) getProducts(w http.ResponseWriter, r *http.Request) {
+	products, err := s.db().SearchProduct(r.URL.Query().Get("search"))
+	if err != nil {
+		s.logger.Error("could not list products", "error", err)
+		writeError(w, http.StatusInternalServerError, "could not list products")
+		return
+	}
+
+	if products == nil {
+		products = []models.ProductWithPrice{}
+	}
+
+	writeJSON(w, http.StatusOK, products)
+}
+
+func (s *ApiService) getProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	product, price, err := s.db().GetProductIdent(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "product not found")
+		return
+	}
+	if err != nil {
+		s.logger.Error("could not get product", "error", err)
+		writeError(w, http.StatusInternalServerError, "could not get product")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.ProductWithPrice{Product: product, Price: price})
+}
+
+func (s *ApiService) getUpc(w http.ResponseWriter, r *http.Request) {
+	db := s.db()
+
+	lookup, err := db.GetUpcType(r.PathValue("upc"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "unknown upc")
+		return
+	}
+	if err != nil {
+		s.logger.Error("could not look up upc", "error", err)
+		writeError(w, http.StatusInternalServerError, "could not look up upc")
+		return
+	}
+
+	switch lookup.Type {
+	case "product":
+		id, _ := strconv.ParseInt(lookup.ReferableId, 10, 64)
+		product, price, err := db.GetProductIdent(id)
+		if err != nil {
+			s.logger.Error("could not get product", "error", err)
+			writeError(w, http.StatusInternalServerError, "could not get product")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"type":    "product",
+			"product": product,
+			"price":   price,
+		})
+	case "user":
+		user, balance, err := db.GetUser(lookup.ReferableId)
+		if err != nil {
+			s.logger.Error("could not get user", "error", err)
+			writeError(w, http.StatusInternalServerError, "could not get user")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"type":    "user",
+			"user":    user,
+			"balance": balance,
+		})
+	default:
+		writeError(w, http.StatusNotFound, "unknown upc")
+	}
+}
+
+// leaderboardQuery returns the kiosk's leaderboard, changed by the window,
+// metric, product and category query parameters.
+func (s *ApiService) leaderboardQuery(r *http.Request) (models.LeaderboardQuery, error) {
+	query := leaderboard.Default(s.leaderboardConfig())
+
+	params := r.URL.Query()
+	if window := params.Get("window"); window != "" {
+		query.Window = window
+	}
+	if metric := params.Get("metric"); metric != "" {
+		query.Metric = metric
+	}
+	if product := params.Get("product"); product != "" {
+		id, err := strconv.ParseInt(product, 10, 64)
+		if err != nil {
+			return query, errors.New("invalid product id")
+		}
+		query.ProductID = id
+	}
+	if params.Has("category") {
+		query.Category = params.Get("category")
+	}
+
+	return query, nil
+}
+
+func (s *ApiService) getLeaderboard(w http.ResponseWriter, r *http.Request) {
+	query, err := s.leaderboardQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	standings, err := leaderboard.Get(s.db(), s.leaderboardConfig(), query)
+	if errors.Is(err, leaderboard.ErrUnknownWindow) || errors.Is(err, leaderboard.ErrUnknownMetric) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		s.logger.Error("could not get leaderboard", "error", err)
+		writeError(w, http.StatusInternalServerError, "could not get leaderboard")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, standings)
+}
+
+// leaderboard returns the kiosk's leaderboard.
+func (s *ApiService) leaderboard() ([]models.TransactionLeaderboard, error) {
+	config := s.leaderboardConfig()
+	return leaderboard.Get(s.db(), config, leaderboard.Default(config))
+}
+
+func (s *ApiService) leaderboardConfig() env.LeaderboardConfig {
+	return s.container.Get(static.DiConfig).(env.Config).Leaderboard
+}
+
+func (s *ApiService) getLatestTransactions(w http.ResponseWriter, r *http.Request) {
+	query, err := s.leaderboardQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	transactions, err := leaderboard.Latest(s.db(), s.leaderboardConfig(), query)
+	if errors.Is(err, leaderboard.ErrUnknownWindow) || errors.Is(err, leaderboard.ErrUnknownMetric) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		s.logger.Error("could not get transactions", "error", err)
+		writeError(w, http.StatusInternalServerError, "could not get transactions")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transactions)
+}
+
+type streckaRequest struct {
+	UserID    string `json:"user_id"`
+	ProductID int64  `json:"product_id"`
+	Amount    int64  `json:"amount"`
+}
+
+func (s *ApiService) postStrecka(w http.ResponseWriter, r *http.Request) {
+	var req streckaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.Amount == 0 {
+		req.Amount = 1
+	}
+	if req.UserID == "" || req.ProductID == 0 || req.Amount < 0 {
+		writeError(w, http.StatusBadRequest, "user_id, product_id and a positive amount are required")
+		return
+	}
+
+	db := s.db()
+
+	user, _, err := db.GetUser(req.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	if err != nil {
+		s.logger.Error("could not get user", "error", err)
+		writeError(w, http.StatusInternalServerError, "could not get user")
+		return
+	}
+
+	origin := models.Origin{
+		Source:    models.OriginApi,
+		Reference: "token:" + requestToken(r).Name,
+	}
+
+	_, err = db.Strecka(origin, user, req.ProductID, req.Amount)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "product not found")
+		return
+	}
+	if err != nil {
+		s.logger.Error("could not strecka", "error", err)
+		writeError(w, http.StatusInternalServerError, "could not strecka")
+		return
+	}
+
+	user, balance, err := db.GetUser(req.UserID)
+	if err != nil {
+		s.logger.Error("could not get user", "error", err)
+		writeError(w, http.StatusInternalServerError, "could not get user")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, models.UserWithBalance{User: user, Balance: balance})
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gostrecka/services/events"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// clientBuffer is the number of messages a stream client may lag
+	// behind before messages are dropped for it.
+	clientBuffer = 64
+
+	heartbeatInterval = 30 * time.Second
+)
+
+// feed fans out domain events as Server-Sent Events to every connected
+// stream client.
+type feed struct {
+	api *ApiService
+
+	mu      sync.Mutex
+	clients map[chan []byte]struct{}
+}
+
+func newFeed(api *ApiService) *feed {
+	return &feed{
+		api:     api,
+		clients: make(map[chan []byte]struct{}),
+	}
+}
+
+// listen subscribes the feed to the event bus.
+func (f *feed) listen(bus *events.Bus) {
+	bus.SubscribeAll("api_stream", func(event events.Event) {
+		// The API only serves the default database, the ledgers of guilds
+		// with one of their own are not streamed.
+		switch e := event.(type) {
+		case events.TransactionCreated:
+			if e.Guild != "" {
+				return
+			}
+		case events.TransactionReversed:
+			if e.Guild != "" {
+				return
+			}
+		case events.StockAdded:
+			if e.Guild != "" {
+				return
+			}
+		case events.PriceChanged:
+			if e.Guild != "" {
+				return
+			}
+		default:
+			return
+		}
+
+		f.broadcast(event.Name(), event)
+
+		switch event.(type) {
+		case events.TransactionCreated, events.TransactionReversed:
+			f.broadcastLeaderboard()
+		}
+	})
+}
+
+func (f *feed) broadcastLeaderboard() {
+	leaderboard, err := f.api.leaderboard()
+	if err != nil {
+		f.api.logger.Error("could not get leaderboard for stream", "error", err)
+		return
+	}
+
+	f.broadcast("leaderboard_updated", leaderboard)
+}
+
+func (f *feed) broadcast(name string, data any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.clients) == 0 {
+		return
+	}
+
+	message, err := formatEvent(name, data)
+	if err != nil {
+		f.api.logger.Error("could not encode stream event", "event", name, "error", err)
+		return
+	}
+
+	for client := range f.clients {
+		select {
+		case client <- message:
+		default:
+			f.api.logger.Warn("Stream client is falling behind, dropping event", "event", name)
+		}
+	}
+}
+
+func (f *feed) add() chan []byte {
+	client := make(chan []byte, clientBuffer)
+
+	f.mu.Lock()
+	f.clients[client] = struct{}{}
+	f.mu.Unlock()
+
+	return client
+}
+
+func (f *feed) remove(client chan []byte) {
+	f.mu.Lock()
+	delete(f.clients, client)
+	f.mu.Unlock()
+}
+
+func formatEvent(name string, data any) ([]byte, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "event: %s\ndata: %s\n\n", name, payload)
+	return buf.Bytes(), nil
+}
+
+// getStream streams transactions, stock changes and leaderboard updates
+// until the client disconnects.
+func (s *ApiService) getStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	client := s.feed.add()
+	defer s.feed.remove(client)
+
+	// Send the current standings so new clients can render right away.
+	if leaderboard, err := s.leaderboard(); err == nil {
+		if message, err := formatEvent("leaderboard_updated", leaderboard); err == nil {
+			w.Write(message)
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.closing:
+			return
+		case message := <-client:
+			if _, err := w.Write(message); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package audit
+
+import (
+	"gostrecka/models"
+	"gostrecka/services/database"
+	"log"
+
+	"github.com/sarulabs/di/v2"
+)
+
+type AuditService struct {
+	container di.Container
+}
+
+func New(container di.Container) *AuditService {
+	return &AuditService{
+		container: container,
+	}
+}
+
+func (a *AuditService) GetAuditLog(filter models.AuditFilter) []models.AuditEntry {
+	db := a.container.Get("database").(database.Database)
+	entries, err := db.GetAuditLog(filter)
+
+	if err != nil {
+		log.Printf("error getting audit log: %v", err)
+		return []models.AuditEntry{}
+	}
+
+	return entries
+}
@@ -0,0 +1,215 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"gostrecka/internal/utils/static"
+	"gostrecka/services/database"
+	"gostrecka/services/database/sqlite"
+	"gostrecka/services/env"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sarulabs/di/v2"
+)
+
+const (
+	filePrefix = "local-"
+	fileSuffix = ".db"
+	timeLayout = "20060102T150405"
+)
+
+type BackupService struct {
+	container di.Container
+	logger    *slog.Logger
+	config    env.BackupConfig
+}
+
+func New(container di.Container) *BackupService {
+	return &BackupService{
+		container: container,
+		logger:    container.Get("logger").(*slog.Logger).With("service", "BACKUP"),
+		config:    container.Get(static.DiConfig).(env.Config).Backup,
+	}
+}
+
+// Snapshot writes a new backup of the database of a guild, or of the
+// default database when guildId is empty or the guild has none of its own,
+// and removes that database's snapshots beyond the retention limit. It
+// returns the path of the new snapshot.
+func (s *BackupService) Snapshot(guildId string) (path string, err error) {
+	dir := s.dir(guildId)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("could not create backup directory: %w", err)
+	}
+
+	path = filepath.Join(dir, filePrefix+time.Now().Format(timeLayout)+fileSuffix)
+
+	db := s.container.Get(static.DiGuilds).(*database.Guilds).Get(guildId)
+	if err = db.Backup(path); err != nil {
+		return "", err
+	}
+
+	s.logger.Info("Backup written", "guild", guildId, "path", path)
+
+	if err = s.rotate(dir); err != nil {
+		s.logger.Error("Could not remove old backups", "guild", guildId, "error", err)
+	}
+
+	return path, nil
+}
+
+// SnapshotAll backs up the default database and the database of every
+// guild with one of its own. A failing database does not stop the others.
+func (s *BackupService) SnapshotAll() (paths []string, err error) {
+	guildIds := append([]string{""}, s.container.Get(static.DiGuilds).(*database.Guilds).IDs()...)
+
+	var errs []error
+	for _, guildId := range guildIds {
+		path, err := s.Snapshot(guildId)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("guild %q: %w", guildId, err))
+			continue
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, errors.Join(errs...)
+}
+
+// List returns the paths of all snapshots of a guild's database, newest
+// first.
+func (s *BackupService) List(guildId string) (paths []string, err error) {
+	return list(s.dir(guildId))
+}
+
+// Latest returns the path of the newest snapshot of a guild's database.
+func (s *BackupService) Latest(guildId string) (string, error) {
+	dir := s.dir(guildId)
+	paths, err := list(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(paths) == 0 {
+		return "", fmt.Errorf("no backups found in %s", dir)
+	}
+
+	return paths[0], nil
+}
+
+// dir returns where the snapshots of a guild's database are kept. The
+// default database keeps them directly in the backup directory and every
+// guild with a database of its own in a directory named after the guild.
+func (s *BackupService) dir(guildId string) string {
+	if guildId != "" && s.container.Get(static.DiGuilds).(*database.Guilds).Has(guildId) {
+		return filepath.Join(s.config.Dir, guildId)
+	}
+
+	return s.config.Dir
+}
+
+func (s *BackupService) rotate(dir string) error {
+	if s.config.Retention <= 0 {
+		return nil
+	}
+
+	paths, err := list(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths[min(len(paths), s.config.Retention):] {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		s.logger.Info("Removed old backup", "path", path)
+	}
+
+	return nil
+}
+
+func list(dir string) (paths []string, err error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, name))
+	}
+
+	// The timestamp layout sorts lexically in chronological order.
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+
+	return paths, nil
+}
+
+// Restore replaces the database at dbPath with the snapshot at path. The
+// snapshot is verified first and the current database is kept next to it
+// with a ".pre-restore" suffix. It must be called before the database is
+// opened.
+func Restore(dbPath string, path string, logger *slog.Logger) error {
+	if err := sqlite.VerifySnapshot(path); err != nil {
+		return fmt.Errorf("snapshot %s is not valid: %w", path, err)
+	}
+
+	// Copy into the target directory first so the final swap is a rename.
+	tmpPath := dbPath + ".restore"
+	if err := copyFile(path, tmpPath); err != nil {
+		return fmt.Errorf("could not copy snapshot: %w", err)
+	}
+
+	keepPath := fmt.Sprintf("%s.pre-restore-%s", dbPath, time.Now().Format(timeLayout))
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		err := os.Rename(dbPath+suffix, keepPath+suffix)
+		if err != nil && !os.IsNotExist(err) {
+			os.Remove(tmpPath)
+			return fmt.Errorf("could not move current database aside: %w", err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		return fmt.Errorf("could not swap in snapshot: %w", err)
+	}
+
+	logger.Info("Database restored", "snapshot", path, "previous", keepPath)
+	return nil
+}
+
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+
+	if err = out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
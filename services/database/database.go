@@ -1,25 +1,46 @@
 package database
 
-import "gostrecka/models"
+import (
+	"gostrecka/models"
+	"time"
+)
 
+// Database is the storage of a ledger. Every method that changes users,
+// products, stock, transactions or payments takes the origin of the change
+// and writes an audit log entry in the same transaction. MarkReminded,
+// SaveJobRun and UnlockBadge are exempt: they only keep the bot's own
+// bookkeeping of when a user was reminded, how a job ran and which badges
+// a user has, which the bot derives itself and which moves no money.
 type Database interface {
 	Connect() error
 	Close()
 	Status() error
 
+	/* Maintenance */
+	Backup(path string) error
+
 	/* Users */
 	GetUser(id string) (user models.User, balance models.Balance, err error)
-	CreateUser(id string, name string) error
+	GetUsers() (users []models.UserWithBalance, err error)
+	CreateUser(origin models.Origin, id string, name string) error
+	GetUserSettings(userId string) (settings models.UserSettings, err error)
+	UpdateUserSettings(origin models.Origin, settings models.UserSettings) error
+	MarkReminded(userId string, at time.Time) error
 
 	/* Products */
 	GetProductIdent(id int64) (product models.Product, price models.ProductPrice, err error)
 	SearchProduct(name string) (products []models.ProductWithPrice, err error)
-	CreateProduct(name string, purchasePrice float64, internalPrice float64, externalPrice float64) error
+	CreateProduct(origin models.Origin, name string, purchasePrice float64, internalPrice float64, externalPrice float64) (id int64, err error)
+	CreateProducts(origin models.Origin, products []models.NewProduct, stockUser string) (ids []int64, err error)
+	GetPriceHistory() (prices []models.ProductWithPrice, err error)
 
-	UpdatePrice(productId int64, purchasePrice float64, internalPrice float64, externalPrice float64) error
+	UpdatePrice(origin models.Origin, productId int64, purchasePrice float64, internalPrice float64, externalPrice float64) error
+	SetProductCategory(origin models.Origin, productId int64, category string) error
+	RenameProduct(origin models.Origin, productId int64, name string) error
+	SetProductUpc(origin models.Origin, productId int64, upc string) error
 
 	/* Stock */
-	AddStock(productId int64, userId string, amount int64) error
+	AddStock(origin models.Origin, productId int64, userId string, amount int64) error
 
 	/* UPCs */
 	GetUpcType(upc string) (lookup models.UpcLookup, err error)
@@ -27,7 +48,34 @@ type Database interface {
 	GetProductUpcs() (upcs []models.Upc, err error)
 
 	/* Transactions */
-	Strecka(user models.User, productId int64, amount int64) error
-	GetLatestTransactions() (transactions []models.LatestTransaction, err error)
-	GetTransactionLeaderboard() (leaderboard []models.TransactionLeaderboard, err error)
+	Strecka(origin models.Origin, user models.User, productId int64, amount int64) (transactionId int64, err error)
+	GetTransaction(transactionId int64) (transaction models.TransactionRecord, err error)
+	ReverseTransaction(origin models.Origin, transactionId int64) error
+	DisputeTransaction(origin models.Origin, transactionId int64) error
+	GetLatestTransactions(filter models.LeaderboardFilter) (transactions []models.LatestTransaction, err error)
+	GetTransactionLeaderboard(filter models.LeaderboardFilter) (leaderboard []models.TransactionLeaderboard, err error)
+	GetTransactions(from time.Time, to time.Time) (transactions []models.TransactionRecord, err error)
+	GetUserTransactions(filter models.TransactionFilter) (transactions []models.TransactionRecord, total int, err error)
+
+	/* Payments */
+	RecordPayment(origin models.Origin, userId string, amount float64, paymentType string) (paymentId int64, err error)
+
+	/* Statements */
+	GetStatement(userId string, from time.Time, to time.Time) (statement models.Statement, err error)
+	GetLedger(from time.Time, to time.Time) (entries []models.LedgerEntry, err error)
+
+	/* Reports */
+	GetSales(from time.Time, to time.Time) (sales []models.ProductSales, err error)
+
+	/* Jobs */
+	GetJobRuns() (runs []models.JobRun, err error)
+	SaveJobRun(run models.JobRun) error
+
+	/* Badges */
+	GetBadges(userId string) (badges []models.Badge, err error)
+	GetBadgeStats(userId string) (stats models.BadgeStats, err error)
+	UnlockBadge(userId string, badge string, at time.Time) (unlocked bool, err error)
+
+	/* Audit */
+	GetAuditLog(filter models.AuditFilter) (entries []models.AuditEntry, err error)
 }
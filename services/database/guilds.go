@@ -0,0 +1,57 @@
+package database
+
+import "sort"
+
+// Guilds holds the database of each guild the bot serves, so every guild
+// has users, products and a ledger of its own.
+type Guilds struct {
+	// Default is the database of guilds without one of their own and of
+	// direct messages.
+	Default   Database
+	databases map[string]Database
+}
+
+func NewGuilds(defaultDb Database) *Guilds {
+	return &Guilds{
+		Default:   defaultDb,
+		databases: map[string]Database{},
+	}
+}
+
+// Add sets the database of a guild.
+func (g *Guilds) Add(guildId string, db Database) {
+	g.databases[guildId] = db
+}
+
+// Get returns the database of a guild, or Default when the guild has none
+// or the id is empty.
+func (g *Guilds) Get(guildId string) Database {
+	if db, ok := g.databases[guildId]; ok {
+		return db
+	}
+
+	return g.Default
+}
+
+// Has reports whether a guild has a database of its own.
+func (g *Guilds) Has(guildId string) bool {
+	_, ok := g.databases[guildId]
+	return ok
+}
+
+// IDs returns the guilds with a database of their own, sorted.
+func (g *Guilds) IDs() (ids []string) {
+	for id := range g.databases {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return
+}
+
+// Close closes the databases added with Add.
+func (g *Guilds) Close() {
+	for _, db := range g.databases {
+		db.Close()
+	}
+}
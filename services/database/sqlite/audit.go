@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"gostrecka/models"
+	"strings"
+)
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// writeAudit appends an entry to the audit log. It should be called with
+// the same transaction as the mutation it describes.
+func writeAudit(tx execer, origin models.Origin, action string, entityType string, entityID string, targetUserID string, before any, after any) error {
+	if err := origin.Validate(); err != nil {
+		return err
+	}
+
+	beforeJson, err := marshalAudit(before)
+	if err != nil {
+		return err
+	}
+	afterJson, err := marshalAudit(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO audit_log (actor_id, target_user_id, source, reference, action, entity_type, entity_id, before, after)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		nullable(origin.ActorID),
+		nullable(targetUserID),
+		origin.Source,
+		nullable(origin.Reference),
+		action,
+		entityType,
+		nullable(entityID),
+		beforeJson,
+		afterJson,
+	)
+
+	return err
+}
+
+func marshalAudit(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func nullable(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func (m *SqliteMiddleware) GetAuditLog(filter models.AuditFilter) (entries []models.AuditEntry, err error) {
+	var where []string
+	var args []any
+
+	if filter.ActorID != "" {
+		where = append(where, "actor_id = ?")
+		args = append(args, filter.ActorID)
+	}
+	if filter.TargetUserID != "" {
+		where = append(where, "target_user_id = ?")
+		args = append(args, filter.TargetUserID)
+	}
+	if filter.Source != "" {
+		where = append(where, "source = ?")
+		args = append(args, filter.Source)
+	}
+	if filter.Action != "" {
+		where = append(where, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if !filter.From.IsZero() {
+		where = append(where, "datetime(created_at) >= datetime(?, 'unixepoch')")
+		args = append(args, filter.From.Unix())
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "datetime(created_at) < datetime(?, 'unixepoch')")
+		args = append(args, filter.To.Unix())
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	query := `
+		SELECT
+			id,
+			DATETIME(created_at),
+			COALESCE(actor_id, ''),
+			COALESCE(target_user_id, ''),
+			source,
+			COALESCE(reference, ''),
+			action,
+			entity_type,
+			COALESCE(entity_id, ''),
+			COALESCE(before, ''),
+			COALESCE(after, '')
+		FROM
+			audit_log`
+	if len(where) > 0 {
+		query += "\n\t\tWHERE\n\t\t\t" + strings.Join(where, "\n\t\t\tAND ")
+	}
+	query += "\n\t\tORDER BY\n\t\t\tid DESC\n\t\tLIMIT ?"
+	args = append(args, limit)
+
+	rows, err := m.Db.Query(query, args...)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var entry models.AuditEntry
+		err = rows.Scan(
+			&entry.ID,
+			&entry.CreatedAt,
+			&entry.ActorID,
+			&entry.TargetUserID,
+			&entry.Source,
+			&entry.Reference,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.Before,
+			&entry.After,
+		)
+
+		if err != nil {
+			return
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
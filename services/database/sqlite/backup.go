@@ -0,0 +1,45 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Backup writes a consistent copy of the live database to path using
+// VACUUM INTO. The target file must not exist.
+func (m *SqliteMiddleware) Backup(path string) error {
+	_, err := m.Db.Exec("VACUUM INTO ?", path)
+	if err != nil {
+		m.Logger.Error("could not back up database", "path", path, "error", err.Error())
+	}
+
+	return err
+}
+
+// VerifySnapshot opens the database file at path and checks that it is
+// intact and was created by this application.
+func VerifySnapshot(path string) error {
+	db, err := sql.Open("libsql", fmt.Sprintf("file:%s", path))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var result string
+	if err = db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("integrity check failed: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("integrity check failed: %s", result)
+	}
+
+	var migrations int
+	if err = db.QueryRow("SELECT COUNT(*) FROM migrations").Scan(&migrations); err != nil {
+		return fmt.Errorf("not a strecka database: %w", err)
+	}
+	if migrations == 0 {
+		return fmt.Errorf("not a strecka database: no migrations applied")
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"gostrecka/models"
+	"time"
+)
+
+// GetBadges returns the badges the user has unlocked, oldest first.
+func (m *SqliteMiddleware) GetBadges(userId string) (badges []models.Badge, err error) {
+	rows, err := m.Db.Query(`
+		SELECT user_id, badge, DATETIME(unlocked_at)
+		FROM badges
+		WHERE user_id = $1
+		ORDER BY unlocked_at, badge
+	`, userId)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var badge models.Badge
+		if err = rows.Scan(&badge.UserID, &badge.Badge, &badge.UnlockedAt); err != nil {
+			return
+		}
+
+		badges = append(badges, badge)
+	}
+
+	return badges, rows.Err()
+}
+
+func (m *SqliteMiddleware) GetBadgeStats(userId string) (stats models.BadgeStats, err error) {
+	err = m.Db.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM transactions WHERE user_id = $1),
+			(SELECT COALESCE(SUM(quantity), 0) FROM transactions WHERE user_id = $1),
+			(SELECT COUNT(DISTINCT product_id) FROM transactions WHERE user_id = $1),
+			(SELECT COUNT(*) FROM products),
+			(SELECT COALESCE(SUM(quantity), 0) FROM product_stock WHERE added_by = $1),
+			(SELECT COALESCE(MAX(added), 0) FROM (SELECT SUM(quantity) AS added FROM product_stock GROUP BY added_by))
+	`, userId).Scan(
+		&stats.Transactions,
+		&stats.Units,
+		&stats.ProductsTried,
+		&stats.Products,
+		&stats.StockAdded,
+		&stats.MostStockAdded,
+	)
+
+	return
+}
+
+// UnlockBadge records that the user has unlocked the badge. unlocked is
+// false when they already had it.
+func (m *SqliteMiddleware) UnlockBadge(userId string, badge string, at time.Time) (unlocked bool, err error) {
+	result, err := m.Db.Exec(`
+		INSERT INTO badges (user_id, badge, unlocked_at)
+		VALUES ($1, $2, DATETIME($3, 'unixepoch'))
+		ON CONFLICT (user_id, badge) DO NOTHING
+	`, userId, badge, at.Unix())
+	if err != nil {
+		return
+	}
+
+	added, err := result.RowsAffected()
+	return added > 0, err
+}
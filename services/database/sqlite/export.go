@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"database/sql"
+	"gostrecka/models"
+	"time"
+)
+
+// GetTransactions returns every transaction in the period [from, to),
+// oldest first.
+func (m *SqliteMiddleware) GetTransactions(from time.Time, to time.Time) (transactions []models.TransactionRecord, err error) {
+	rows, err := m.Db.Query(`
+		SELECT
+			t.id,
+			DATETIME(t.transaction_date),
+			COALESCE(t.user_id, ''),
+			COALESCE(u.name, ''),
+			t.product_id,
+			p.name,
+			t.quantity,
+			t.price_type,
+			t.price_paid
+		FROM
+			transactions t
+		LEFT JOIN
+			users u ON t.user_id = u.id
+		JOIN
+			products p ON t.product_id = p.id
+		WHERE
+			datetime(t.transaction_date) >= datetime($1, 'unixepoch')
+			AND datetime(t.transaction_date) < datetime($2, 'unixepoch')
+		ORDER BY
+			t.transaction_date ASC, t.id ASC
+	`, from.Unix(), to.Unix())
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var transaction models.TransactionRecord
+		err = rows.Scan(
+			&transaction.ID,
+			&transaction.TransactionDate,
+			&transaction.UserID,
+			&transaction.UserName,
+			&transaction.ProductID,
+			&transaction.ProductName,
+			&transaction.Quantity,
+			&transaction.PriceType,
+			&transaction.PricePaid,
+		)
+
+		if err != nil {
+			return
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+// GetPriceHistory returns every price a product has had, with the current
+// stock, ordered by product and start date.
+func (m *SqliteMiddleware) GetPriceHistory() (prices []models.ProductWithPrice, err error) {
+	rows, err := m.Db.Query(`
+		SELECT
+			c.product_id,
+			c.name,
+			c.total_stock,
+			pr.category,
+			p.id,
+			p.purchase_price,
+			p.internal_price,
+			p.external_price,
+			DATETIME(p.start_date),
+			DATETIME(p.end_date)
+		FROM
+			current_stock c
+		JOIN
+			products pr ON c.product_id = pr.id
+		JOIN
+			product_price p ON c.product_id = p.product_id
+		ORDER BY
+			c.product_id ASC, p.start_date ASC
+	`)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var product models.Product
+		var price models.ProductPrice
+		var endDate sql.NullTime
+
+		err = rows.Scan(
+			&product.ID,
+			&product.Name,
+			&product.TotalStock,
+			&product.Category,
+			&price.ID,
+			&price.PurchasePrice,
+			&price.InternalPrice,
+			&price.ExternalPrice,
+			&price.StartDate,
+			&endDate,
+		)
+
+		if err != nil {
+			return
+		}
+
+		price.ProductID = product.ID
+		if endDate.Valid {
+			price.EndDate = endDate.Time
+		}
+
+		prices = append(prices, models.ProductWithPrice{Product: product, Price: price})
+	}
+
+	return prices, rows.Err()
+}
@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"gostrecka/models"
+	"strings"
+)
+
+// GetUserTransactions returns a page of a user's transactions, newest
+// first, and how many transactions the filter matches in total.
+func (m *SqliteMiddleware) GetUserTransactions(filter models.TransactionFilter) (transactions []models.TransactionRecord, total int, err error) {
+	where := []string{"t.user_id = ?"}
+	args := []any{filter.UserID}
+
+	if !filter.From.IsZero() {
+		where = append(where, "datetime(t.transaction_date) >= datetime(?, 'unixepoch')")
+		args = append(args, filter.From.Unix())
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "datetime(t.transaction_date) < datetime(?, 'unixepoch')")
+		args = append(args, filter.To.Unix())
+	}
+
+	conditions := strings.Join(where, "\n\t\t\tAND ")
+
+	err = m.Db.QueryRow(`
+		SELECT
+			COUNT(*)
+		FROM
+			transactions t
+		WHERE
+			`+conditions, args...).Scan(&total)
+	if err != nil {
+		return
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	rows, err := m.Db.Query(`
+		SELECT
+			t.id,
+			DATETIME(t.transaction_date),
+			t.user_id,
+			COALESCE(u.name, ''),
+			t.product_id,
+			p.name,
+			t.quantity,
+			t.price_type,
+			t.price_paid
+		FROM
+			transactions t
+		LEFT JOIN
+			users u ON t.user_id = u.id
+		JOIN
+			products p ON t.product_id = p.id
+		WHERE
+			`+conditions+`
+		ORDER BY
+			t.transaction_date DESC, t.id DESC
+		LIMIT ? OFFSET ?
+	`, append(args, limit, filter.Offset)...)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var transaction models.TransactionRecord
+		err = rows.Scan(
+			&transaction.ID,
+			&transaction.TransactionDate,
+			&transaction.UserID,
+			&transaction.UserName,
+			&transaction.ProductID,
+			&transaction.ProductName,
+			&transaction.Quantity,
+			&transaction.PriceType,
+			&transaction.PricePaid,
+		)
+
+		if err != nil {
+			return
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, total, rows.Err()
+}
@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"database/sql"
+	"gostrecka/models"
+)
+
+func (m *SqliteMiddleware) GetJobRuns() (runs []models.JobRun, err error) {
+	rows, err := m.Db.Query(`
+		SELECT name, DATETIME(started_at), DATETIME(finished_at), status, COALESCE(error, '')
+		FROM job_runs
+		ORDER BY name
+	`)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var run models.JobRun
+		var finished sql.NullTime
+
+		err = rows.Scan(&run.Name, &run.StartedAt, &finished, &run.Status, &run.Error)
+		if err != nil {
+			return
+		}
+
+		if finished.Valid {
+			run.FinishedAt = finished.Time
+		}
+
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// SaveJobRun replaces the recorded run of the job.
+func (m *SqliteMiddleware) SaveJobRun(run models.JobRun) error {
+	var finished any
+	if !run.FinishedAt.IsZero() {
+		finished = run.FinishedAt.Unix()
+	}
+
+	_, err := m.Db.Exec(`
+		INSERT INTO job_runs (name, started_at, finished_at, status, error)
+		VALUES ($1, DATETIME($2, 'unixepoch'), DATETIME($3, 'unixepoch'), $4, $5)
+		ON CONFLICT (name) DO UPDATE SET
+			started_at = excluded.started_at,
+			finished_at = excluded.finished_at,
+			status = excluded.status,
+			error = excluded.error
+	`, run.Name, run.StartedAt.Unix(), finished, run.Status, nullable(run.Error))
+
+	return err
+}
@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"gostrecka/models"
+	"time"
+)
+
+// GetLedger returns daily totals of sales, stock credited to users,
+// payments and write-offs in the period [from, to), oldest first. Days are
+// in local time and internal sales are priced the same way as the
+// user_credits view.
+func (m *SqliteMiddleware) GetLedger(from time.Time, to time.Time) (entries []models.LedgerEntry, err error) {
+	rows, err := m.Db.Query(`
+		SELECT day, kind, ROUND(SUM(amount), 2)
+		FROM (
+			SELECT
+				DATE(t.transaction_date, 'localtime') AS day,
+				t.transaction_date AS date,
+				CASE t.price_type WHEN 'external' THEN 'external_sale' ELSE 'internal_sale' END AS kind,
+				t.quantity * CASE t.price_type WHEN 'external' THEN t.price_paid ELSE COALESCE(pp.internal_price, 0) END AS amount
+			FROM
+				transactions t
+			LEFT JOIN
+				product_price pp ON t.product_id = pp.product_id
+					AND t.transaction_date BETWEEN pp.start_date AND IFNULL(pp.end_date, DATETIME('now'))
+
+			UNION ALL
+
+			SELECT
+				DATE(ps.added_date, 'localtime'),
+				ps.added_date,
+				'stock',
+				ps.quantity * COALESCE(pp.purchase_price, 0)
+			FROM
+				product_stock ps
+			LEFT JOIN
+				product_price pp ON ps.product_id = pp.product_id
+					AND ps.added_date BETWEEN pp.start_date AND IFNULL(pp.end_date, DATETIME('now'))
+
+			UNION ALL
+
+			SELECT
+				DATE(up.payment_date, 'localtime'),
+				up.payment_date,
+				up.payment_type,
+				up.payment_amount
+			FROM
+				user_payments up
+		)
+		WHERE
+			datetime(date) >= datetime($1, 'unixepoch')
+			AND datetime(date) < datetime($2, 'unixepoch')
+		GROUP BY
+			day, kind
+		HAVING
+			SUM(amount) != 0
+		ORDER BY
+			day ASC, kind ASC
+	`, from.Unix(), to.Unix())
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var entry models.LedgerEntry
+		var day time.Time
+
+		if err = rows.Scan(&day, &entry.Kind, &entry.Amount); err != nil {
+			return
+		}
+
+		// The driver reads the local date as midnight UTC.
+		entry.Date = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -35,6 +35,13 @@ func (m SqliteMiddleware) Migrate(conn *sql.DB) error {
 
 	var files []Migration = []Migration{
 		{Name: "20240809205925_initial", Content: sqlite_migrations.MIGRATION1},
+		{Name: "20261019120000_audit_log", Content: sqlite_migrations.MIGRATION2},
+		{Name: "20261019140000_user_settings", Content: sqlite_migrations.MIGRATION3},
+		{Name: "20261019150000_job_runs", Content: sqlite_migrations.MIGRATION4},
+		{Name: "20261019160000_payment_types", Content: sqlite_migrations.MIGRATION5},
+		{Name: "20261019170000_product_category", Content: sqlite_migrations.MIGRATION6},
+		{Name: "20261019180000_user_locale", Content: sqlite_migrations.MIGRATION7},
+		{Name: "20261019190000_badges", Content: sqlite_migrations.MIGRATION8},
 	}
 
 	for _, file := range files {
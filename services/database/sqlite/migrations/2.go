@@ -0,0 +1,33 @@
+package sqlite_migrations
+
+var MIGRATION2 = `
+-- Append-only log of every mutation
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    created_at INTEGER NOT NULL DEFAULT (DATETIME('now')),
+    actor_id TEXT,
+    target_user_id TEXT,
+    source TEXT NOT NULL,
+    reference TEXT,
+    action TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT,
+    before TEXT,
+    after TEXT
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at ON audit_log (created_at);
+
+CREATE INDEX IF NOT EXISTS audit_log_target_user_id ON audit_log (target_user_id);
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update
+BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete
+BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;`
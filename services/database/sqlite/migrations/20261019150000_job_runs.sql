@@ -0,0 +1,8 @@
+-- Last run of each scheduled job
+CREATE TABLE IF NOT EXISTS job_runs (
+    name TEXT PRIMARY KEY NOT NULL,
+    started_at INTEGER NOT NULL,
+    finished_at INTEGER,
+    status TEXT NOT NULL CHECK(status IN ('running', 'ok', 'error')),
+    error TEXT
+);
@@ -0,0 +1,2 @@
+-- Group products for reporting
+ALTER TABLE products ADD COLUMN category TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+-- Achievements users have unlocked
+CREATE TABLE IF NOT EXISTS badges (
+    user_id TEXT NOT NULL,
+    badge TEXT NOT NULL,
+    unlocked_at INTEGER NOT NULL DEFAULT (DATETIME('now')),
+    PRIMARY KEY (user_id, badge),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
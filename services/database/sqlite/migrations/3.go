@@ -0,0 +1,10 @@
+package sqlite_migrations
+
+var MIGRATION3 = `
+-- Per-user preferences and reminder bookkeeping
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_id TEXT PRIMARY KEY NOT NULL,
+    debt_reminders INTEGER NOT NULL DEFAULT 1,
+    last_reminded_at INTEGER,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);`
@@ -0,0 +1,5 @@
+package sqlite_migrations
+
+var MIGRATION5 = `
+-- Distinguish written off debt from payments received
+ALTER TABLE user_payments ADD COLUMN payment_type TEXT NOT NULL DEFAULT 'payment' CHECK(payment_type IN ('payment', 'write_off'));`
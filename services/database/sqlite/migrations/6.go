@@ -0,0 +1,5 @@
+package sqlite_migrations
+
+var MIGRATION6 = `
+-- Group products for reporting
+ALTER TABLE products ADD COLUMN category TEXT NOT NULL DEFAULT '';`
@@ -0,0 +1,5 @@
+package sqlite_migrations
+
+var MIGRATION7 = `
+-- Language of the bot's responses, empty to follow the Discord client
+ALTER TABLE user_settings ADD COLUMN locale TEXT NOT NULL DEFAULT '';`
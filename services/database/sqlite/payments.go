@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"fmt"
+	"gostrecka/models"
+	"gostrecka/services/events"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RecordPayment registers that a user has paid amount, or that amount of
+// their debt has been written off, which both lower their debt.
+func (m *SqliteMiddleware) RecordPayment(origin models.Origin, userId string, amount float64, paymentType string) (paymentId int64, err error) {
+	if !slices.Contains(models.PaymentTypes, paymentType) {
+		return 0, fmt.Errorf("unknown payment type %q", paymentType)
+	}
+	if amount <= 0 {
+		return 0, fmt.Errorf("payment amount must be positive, got %.02f", amount)
+	}
+
+	tx, err := m.Db.Begin()
+	if err != nil {
+		return
+	}
+
+	row := tx.QueryRow("INSERT INTO user_payments (user_id, payment_amount, payment_date, payment_type) VALUES ($1, $2, datetime('now'), $3) RETURNING id",
+		userId, amount, paymentType)
+
+	var id int64
+	if err = row.Scan(&id); err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = writeAudit(tx, origin, "payment.record", "user_payment", strconv.FormatInt(id, 10), userId, nil, map[string]any{
+		"amount":       amount,
+		"payment_type": paymentType,
+	})
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		return
+	}
+
+	m.publish(events.PaymentRecorded{
+		PaymentID:   id,
+		UserID:      userId,
+		Amount:      amount,
+		PaymentType: paymentType,
+		Origin:      origin,
+		Guild:       m.Guild,
+		CreatedAt:   time.Now(),
+	})
+
+	return id, nil
+}
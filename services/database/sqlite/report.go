@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"gostrecka/models"
+	"time"
+)
+
+// GetSales returns what each product sold per local day in the period
+// [from, to), oldest first.
+func (m *SqliteMiddleware) GetSales(from time.Time, to time.Time) (sales []models.ProductSales, err error) {
+	rows, err := m.Db.Query(`
+		SELECT
+			DATE(t.transaction_date, 'localtime') AS day,
+			p.id,
+			p.name,
+			p.category,
+			SUM(t.quantity),
+			ROUND(SUM(t.quantity * t.price_paid), 2),
+			ROUND(SUM(t.quantity * COALESCE(pp.purchase_price, 0)), 2)
+		FROM
+			transactions t
+		JOIN
+			products p ON t.product_id = p.id
+		LEFT JOIN
+			product_price pp ON t.product_id = pp.product_id
+				AND t.transaction_date BETWEEN pp.start_date AND IFNULL(pp.end_date, DATETIME('now'))
+		WHERE
+			datetime(t.transaction_date) >= datetime($1, 'unixepoch')
+			AND datetime(t.transaction_date) < datetime($2, 'unixepoch')
+		GROUP BY
+			day, p.id
+		ORDER BY
+			day ASC, p.id ASC
+	`, from.Unix(), to.Unix())
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var row models.ProductSales
+		var day time.Time
+
+		err = rows.Scan(
+			&day,
+			&row.ProductID,
+			&row.ProductName,
+			&row.Category,
+			&row.Quantity,
+			&row.Revenue,
+			&row.Cost,
+		)
+
+		if err != nil {
+			return
+		}
+
+		// The driver reads the local date as midnight UTC.
+		row.Date = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+
+		sales = append(sales, row)
+	}
+
+	return sales, rows.Err()
+}
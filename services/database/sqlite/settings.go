@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"gostrecka/models"
+	"time"
+)
+
+// GetUserSettings returns the user's settings, or the defaults when the
+// user has not changed any.
+func (m *SqliteMiddleware) GetUserSettings(userId string) (settings models.UserSettings, err error) {
+	settings = models.UserSettings{UserID: userId, DebtReminders: true}
+
+	var lastReminded sql.NullTime
+	row := m.Db.QueryRow(`
+		SELECT debt_reminders, locale, DATETIME(last_reminded_at)
+		FROM user_settings
+		WHERE user_id = ?
+	`, userId)
+
+	err = row.Scan(&settings.DebtReminders, &settings.Locale, &lastReminded)
+	if errors.Is(err, sql.ErrNoRows) {
+		return settings, nil
+	}
+	if err != nil {
+		return
+	}
+
+	if lastReminded.Valid {
+		settings.LastRemindedAt = lastReminded.Time
+	}
+
+	return
+}
+
+// UpdateUserSettings stores the user's preferences. Reminder bookkeeping
+// is left untouched, see MarkReminded.
+func (m *SqliteMiddleware) UpdateUserSettings(origin models.Origin, settings models.UserSettings) error {
+	before, err := m.GetUserSettings(settings.UserID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.Db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO user_settings (user_id, debt_reminders, locale)
+		VALUES (?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			debt_reminders = excluded.debt_reminders,
+			locale = excluded.locale
+	`, settings.UserID, settings.DebtReminders, settings.Locale)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = writeAudit(tx, origin, "user.settings", "user", settings.UserID, settings.UserID, map[string]any{
+		"debt_reminders": before.DebtReminders,
+		"locale":         before.Locale,
+	}, map[string]any{
+		"debt_reminders": settings.DebtReminders,
+		"locale":         settings.Locale,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MarkReminded records when the user was last sent a debt reminder.
+func (m *SqliteMiddleware) MarkReminded(userId string, at time.Time) error {
+	_, err := m.Db.Exec(`
+		INSERT INTO user_settings (user_id, last_reminded_at)
+		VALUES ($1, DATETIME($2, 'unixepoch'))
+		ON CONFLICT (user_id) DO UPDATE SET last_reminded_at = excluded.last_reminded_at
+	`, userId, at.Unix())
+
+	return err
+}
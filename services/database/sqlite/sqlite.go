@@ -3,13 +3,16 @@ package sqlite
 import (
 	"database/sql"
 	"fmt"
+	"gostrecka/internal/utils/static"
 	"gostrecka/models"
 	"gostrecka/services/database"
 	"gostrecka/services/env"
+	"gostrecka/services/events"
 	"log"
 	"log/slog"
 	"math/rand"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/sarulabs/di/v2"
@@ -20,6 +23,12 @@ type SqliteMiddleware struct {
 	Db        *sql.DB
 	Logger    *slog.Logger
 	Container di.Container
+	// Path is the database file, the configured one unless changed before
+	// Connect.
+	Path string
+	// Guild is the guild the database belongs to, set on the events it
+	// publishes. Empty for the default database.
+	Guild string
 }
 
 var _ database.Database = (*SqliteMiddleware)(nil)
@@ -28,6 +37,7 @@ func New(container di.Container) *SqliteMiddleware {
 	return &SqliteMiddleware{
 		Container: container,
 		Logger:    container.Get("logger").(*slog.Logger).With("service", "SQLITE"),
+		Path:      container.Get("config").(env.Config).DbUrl,
 	}
 }
 
@@ -46,20 +56,18 @@ func (m *SqliteMiddleware) setup() (err error) {
 }
 
 func (m *SqliteMiddleware) Connect() (err error) {
-	cfg := m.Container.Get("config").(env.Config)
-
-	_, err = os.Stat(cfg.DbUrl)
+	_, err = os.Stat(m.Path)
 	if os.IsNotExist(err) {
-		f, err := os.OpenFile(cfg.DbUrl, os.O_RDONLY|os.O_CREATE, 0666)
+		f, err := os.OpenFile(m.Path, os.O_RDONLY|os.O_CREATE, 0666)
 		if err != nil {
 			m.Logger.Error("could not create database file", "error", err.Error())
 			return err
 		}
-		m.Logger.Warn("could not find database file, creating it", "path", cfg.DbUrl)
+		m.Logger.Warn("could not find database file, creating it", "path", m.Path)
 		f.Close()
 	}
 
-	if m.Db, err = sql.Open("libsql", fmt.Sprintf("file:%s", cfg.DbUrl)); err != nil {
+	if m.Db, err = sql.Open("libsql", fmt.Sprintf("file:%s", m.Path)); err != nil {
 		m.Logger.Error("could not open database", "error", err.Error())
 		return err
 	}
@@ -78,6 +86,17 @@ func (m *SqliteMiddleware) Status() error {
 	return m.Db.Ping()
 }
 
+// publish hands the event to the event bus, if one is registered.
+func (m *SqliteMiddleware) publish(event events.Event) {
+	bus, err := m.Container.SafeGet(static.DiEvents)
+	if err != nil {
+		m.Logger.Warn("no event bus, dropping event", "event", event.Name())
+		return
+	}
+
+	bus.(*events.Bus).Publish(event)
+}
+
 func (m *SqliteMiddleware) GetUser(id string) (user models.User, balance models.Balance, err error) {
 
 	row := m.Db.QueryRow("SELECT id, name FROM users WHERE id = ?", id)
@@ -108,17 +127,94 @@ func (m *SqliteMiddleware) GetUser(id string) (user models.User, balance models.
 	return
 }
 
-func (m *SqliteMiddleware) CreateUser(id string, name string) error {
-	_, err := m.Db.Exec("INSERT INTO users (id, name) VALUES (?, ?)", id, name)
+func (m *SqliteMiddleware) GetUsers() (users []models.UserWithBalance, err error) {
+	rows, err := m.Db.Query(`
+		SELECT
+			u.id,
+			u.name,
+			COALESCE(c.total_credits_earned, 0),
+			COALESCE(c.total_payments_made, 0),
+			COALESCE(c.total_debt_incurred, 0),
+			COALESCE(c.remaining_credits, 0),
+			COALESCE(c.debt_incurred, 0)
+		FROM
+			users u
+		LEFT JOIN
+			user_credits c ON u.id = c.user_id
+		ORDER BY
+			u.name ASC
+	`)
+
 	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var user models.UserWithBalance
+		err = rows.Scan(
+			&user.User.ID,
+			&user.User.Name,
+			&user.Balance.TotalCreditsEarned,
+			&user.Balance.TotalPaymentsMade,
+			&user.Balance.TotalDebtIncurred,
+			&user.Balance.RemainingCredits,
+			&user.Balance.DebtIncurred,
+		)
+
+		if err != nil {
+			return
+		}
+
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (m *SqliteMiddleware) CreateUser(origin models.Origin, id string, name string) error {
+	tx, err := m.Db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO users (id, name) VALUES (?, ?)", id, name)
+	if err != nil {
+		tx.Rollback()
 		return err
 	}
 
 	var upc = rand.Intn(90000000) + 10000000
 
-	_, err = m.Db.Exec("INSERT INTO upcs (referable_id, referable_type, upc) VALUES (?, 'user', ?)", id, upc)
-	return err
+	_, err = tx.Exec("INSERT INTO upcs (referable_id, referable_type, upc) VALUES (?, 'user', ?)", id, upc)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = writeAudit(tx, origin, "user.create", "user", id, id, nil, map[string]any{
+		"id":   id,
+		"name": name,
+		"upc":  upc,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	m.publish(events.UserCreated{
+		UserID:    id,
+		UserName:  name,
+		Origin:    origin,
+		Guild:     m.Guild,
+		CreatedAt: time.Now(),
+	})
+
+	return nil
 }
 
 func (m *SqliteMiddleware) GetUpcType(upc string) (lookup models.UpcLookup, err error) {
@@ -128,8 +224,8 @@ func (m *SqliteMiddleware) GetUpcType(upc string) (lookup models.UpcLookup, err
 }
 
 func (m *SqliteMiddleware) GetProductIdent(id int64) (product models.Product, price models.ProductPrice, err error) {
-	row := m.Db.QueryRow("SELECT product_id, name, total_stock FROM current_stock WHERE product_id = ?", id)
-	err = row.Scan(&product.ID, &product.Name, &product.TotalStock)
+	row := m.Db.QueryRow("SELECT c.product_id, c.name, c.total_stock, p.category, COALESCE(u.upc, '') FROM current_stock c JOIN products p ON c.product_id = p.id LEFT JOIN upcs u ON p.upc_id = u.id WHERE c.product_id = ?", id)
+	err = row.Scan(&product.ID, &product.Name, &product.TotalStock, &product.Category, &product.Upc)
 
 	if err != nil {
 		return
@@ -177,6 +273,7 @@ func (m *SqliteMiddleware) SearchProduct(name string) (products []models.Product
 			c.product_id,
 			c.name,
 			c.total_stock,
+			pr.category,
 			p.purchase_price,
 			p.internal_price,
 			p.external_price,
@@ -184,6 +281,8 @@ func (m *SqliteMiddleware) SearchProduct(name string) (products []models.Product
 			COALESCE(datetime(end_date), datetime(9999999999, 'unixepoch')) AS end_date
 		FROM
 			current_stock c
+		JOIN
+			products pr ON c.product_id = pr.id
 		LEFT JOIN 
 			product_price p ON c.product_id = p.product_id
 		WHERE
@@ -210,6 +309,7 @@ func (m *SqliteMiddleware) SearchProduct(name string) (products []models.Product
 			&product.ID,
 			&product.Name,
 			&product.TotalStock,
+			&product.Category,
 			&price.PurchasePrice,
 			&price.InternalPrice,
 			&price.ExternalPrice,
@@ -228,19 +328,77 @@ func (m *SqliteMiddleware) SearchProduct(name string) (products []models.Product
 	return
 }
 
-func (m *SqliteMiddleware) CreateProduct(name string, purchasePrice float64, internalPrice float64, externalPrice float64) error {
+func (m *SqliteMiddleware) CreateProduct(origin models.Origin, name string, purchasePrice float64, internalPrice float64, externalPrice float64) (int64, error) {
 	tx, err := m.Db.Begin()
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	id, err := createProduct(tx, origin, name, purchasePrice, internalPrice, externalPrice)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	m.publish(m.productCreated(origin, id, name, purchasePrice, internalPrice, externalPrice))
+
+	return id, nil
+}
+
+// CreateProducts creates every product and adds its stock, credited to
+// stockUser, in one transaction, so either all of them are created or none.
+func (m *SqliteMiddleware) CreateProducts(origin models.Origin, products []models.NewProduct, stockUser string) (ids []int64, err error) {
+	tx, err := m.Db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	var published []events.Event
+	for _, product := range products {
+		id, err := createProduct(tx, origin, product.Name, product.PurchasePrice, product.InternalPrice, product.ExternalPrice)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		ids = append(ids, id)
+		published = append(published, m.productCreated(origin, id, product.Name, product.PurchasePrice, product.InternalPrice, product.ExternalPrice))
+
+		if product.Stock <= 0 {
+			continue
+		}
+
+		stockId, err := addStock(tx, origin, id, stockUser, product.Stock, 0)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		published = append(published, m.stockAdded(origin, stockId, models.Product{ID: id, Name: product.Name}, stockUser, product.Stock))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	for _, event := range published {
+		m.publish(event)
 	}
 
+	return ids, nil
+}
+
+// createProduct inserts a product with a random barcode and its first
+// price in tx.
+func createProduct(tx *sql.Tx, origin models.Origin, name string, purchasePrice float64, internalPrice float64, externalPrice float64) (int64, error) {
 	row := tx.QueryRow("INSERT INTO products (name) VALUES (?) RETURNING id", name)
-	var id string
-	err = row.Scan(&id)
+	var id int64
+	err := row.Scan(&id)
 	if err != nil {
 		log.Printf("Error creating product: %s", err)
-		tx.Rollback()
-		return err
+		return 0, err
 	}
 
 	var upc = rand.Intn(90000000) + 10000000
@@ -248,8 +406,7 @@ func (m *SqliteMiddleware) CreateProduct(name string, purchasePrice float64, int
 
 	if err != nil {
 		log.Printf("Error creating upc: %s", err)
-		tx.Rollback()
-		return err
+		return 0, err
 	}
 
 	_, err = tx.Exec("INSERT INTO product_price (product_id, purchase_price, internal_price, external_price, start_date) VALUES (?, ?, ?, ?, datetime('now'))",
@@ -257,27 +414,98 @@ func (m *SqliteMiddleware) CreateProduct(name string, purchasePrice float64, int
 
 	if err != nil {
 		log.Printf("Error creating product_price: %s", err)
-		tx.Rollback()
-		return err
+		return 0, err
 	}
 
-	return tx.Commit()
+	err = writeAudit(tx, origin, "product.create", "product", strconv.FormatInt(id, 10), "", nil, map[string]any{
+		"name":           name,
+		"upc":            upc,
+		"purchase_price": purchasePrice,
+		"internal_price": internalPrice,
+		"external_price": externalPrice,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
 }
 
-func (m *SqliteMiddleware) Strecka(user models.User, productId int64, amount int64) error {
+func (m *SqliteMiddleware) productCreated(origin models.Origin, id int64, name string, purchasePrice float64, internalPrice float64, externalPrice float64) events.PriceChanged {
+	return events.PriceChanged{
+		ProductID:   id,
+		ProductName: name,
+		After: models.ProductPrice{
+			ProductID:     id,
+			PurchasePrice: purchasePrice,
+			InternalPrice: internalPrice,
+			ExternalPrice: externalPrice,
+			StartDate:     time.Now(),
+		},
+		Origin:    origin,
+		Guild:     m.Guild,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (m *SqliteMiddleware) Strecka(origin models.Origin, user models.User, productId int64, amount int64) (transactionId int64, err error) {
 
 	product, price, err := m.GetProductIdent(productId)
 	if err != nil {
-		return err
+		return
+	}
+
+	tx, err := m.Db.Begin()
+	if err != nil {
+		return
 	}
 
-	m.Db.Exec("INSERT INTO transactions (user_id, product_id, quantity, price_type, price_paid) VALUES ($1, $2, $3, 'internal', $4)",
+	row := tx.QueryRow("INSERT INTO transactions (user_id, product_id, quantity, price_type, price_paid) VALUES ($1, $2, $3, 'internal', $4) RETURNING id",
 		user.ID, product.ID, amount, price.InternalPrice)
 
-	return nil
+	var id int64
+	if err = row.Scan(&id); err != nil {
+		tx.Rollback()
+		return
+	}
+
+	err = writeAudit(tx, origin, "transaction.create", "transaction", strconv.FormatInt(id, 10), user.ID, nil, map[string]any{
+		"product_id": product.ID,
+		"quantity":   amount,
+		"price_type": "internal",
+		"price_paid": price.InternalPrice,
+	})
+	if err != nil {
+		tx.Rollback()
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		return
+	}
+
+	m.publish(events.TransactionCreated{
+		TransactionID: id,
+		UserID:        user.ID,
+		ProductID:     product.ID,
+		ProductName:   product.Name,
+		Quantity:      amount,
+		PriceType:     "internal",
+		PricePaid:     price.InternalPrice,
+		Origin:        origin,
+		Guild:         m.Guild,
+		CreatedAt:     time.Now(),
+	})
+
+	return id, nil
 }
 
-func (m *SqliteMiddleware) UpdatePrice(productId int64, purchasePrice float64, internalPrice float64, externalPrice float64) error {
+func (m *SqliteMiddleware) UpdatePrice(origin models.Origin, productId int64, purchasePrice float64, internalPrice float64, externalPrice float64) error {
+	product, before, err := m.GetProductIdent(productId)
+	if err != nil {
+		return err
+	}
+
 	tx, err := m.Db.Begin()
 	if err != nil {
 		return err
@@ -299,25 +527,214 @@ func (m *SqliteMiddleware) UpdatePrice(productId int64, purchasePrice float64, i
 		return err
 	}
 
+	err = writeAudit(tx, origin, "product.price", "product", strconv.FormatInt(productId, 10), "", map[string]any{
+		"purchase_price": before.PurchasePrice,
+		"internal_price": before.InternalPrice,
+		"external_price": before.ExternalPrice,
+	}, map[string]any{
+		"purchase_price": purchasePrice,
+		"internal_price": internalPrice,
+		"external_price": externalPrice,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	m.publish(events.PriceChanged{
+		ProductID:   productId,
+		ProductName: product.Name,
+		Before:      &before,
+		After: models.ProductPrice{
+			ProductID:     productId,
+			PurchasePrice: purchasePrice,
+			InternalPrice: internalPrice,
+			ExternalPrice: externalPrice,
+			StartDate:     time.Now(),
+		},
+		Origin:    origin,
+		Guild:     m.Guild,
+		CreatedAt: time.Now(),
+	})
+
+	return nil
+}
+
+func (m *SqliteMiddleware) SetProductCategory(origin models.Origin, productId int64, category string) error {
+	product, _, err := m.GetProductIdent(productId)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.Db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE products SET category = ? WHERE id = ?", category, productId)
+	if err != nil {
+		log.Printf("Error updating product category: %s", err)
+		tx.Rollback()
+		return err
+	}
+
+	err = writeAudit(tx, origin, "product.category", "product", strconv.FormatInt(productId, 10), "", map[string]any{
+		"category": product.Category,
+	}, map[string]any{
+		"category": category,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit()
 }
 
-func (m *SqliteMiddleware) AddStock(productId int64, userId string, amount int64) error {
+func (m *SqliteMiddleware) RenameProduct(origin models.Origin, productId int64, name string) error {
+	product, _, err := m.GetProductIdent(productId)
+	if err != nil {
+		return err
+	}
+
 	tx, err := m.Db.Begin()
 	if err != nil {
 		return err
 	}
 
-	_, err = tx.Exec("INSERT INTO product_stock (product_id, added_by, added_date, quantity) VALUES (?, ?, datetime('now'), ?)", productId, userId, amount)
+	_, err = tx.Exec("UPDATE products SET name = ? WHERE id = ?", name, productId)
 	if err != nil {
-		log.Printf("Error adding stock: %s", err)
+		log.Printf("Error renaming product: %s", err)
 		tx.Rollback()
+		return err
+	}
+
+	err = writeAudit(tx, origin, "product.rename", "product", strconv.FormatInt(productId, 10), "", map[string]any{
+		"name": product.Name,
+	}, map[string]any{
+		"name": name,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
 	}
 
 	return tx.Commit()
 }
 
-func (m *SqliteMiddleware) GetLatestTransactions() (transactions []models.LatestTransaction, err error) {
+// SetProductUpc replaces the barcode a product is scanned with. Products
+// without a barcode get one.
+func (m *SqliteMiddleware) SetProductUpc(origin models.Origin, productId int64, upc string) error {
+	product, _, err := m.GetProductIdent(productId)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.Db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if product.Upc == "" {
+		_, err = tx.Exec("INSERT INTO upcs (referable_id, referable_type, upc) VALUES (?, 'product', ?)", productId, upc)
+	} else {
+		_, err = tx.Exec("UPDATE upcs SET upc = ? WHERE upc = ?", upc, product.Upc)
+	}
+	if err != nil {
+		log.Printf("Error setting product upc: %s", err)
+		tx.Rollback()
+		return err
+	}
+
+	err = writeAudit(tx, origin, "product.upc", "product", strconv.FormatInt(productId, 10), "", map[string]any{
+		"upc": product.Upc,
+	}, map[string]any{
+		"upc": upc,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *SqliteMiddleware) AddStock(origin models.Origin, productId int64, userId string, amount int64) error {
+	product, _, err := m.GetProductIdent(productId)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.Db.Begin()
+	if err != nil {
+		return err
+	}
+
+	id, err := addStock(tx, origin, productId, userId, amount, int64(product.TotalStock))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	m.publish(m.stockAdded(origin, id, product, userId, amount))
+
+	return nil
+}
+
+// addStock adds amount of the product, which has totalStock in stock, in tx.
+func addStock(tx *sql.Tx, origin models.Origin, productId int64, userId string, amount int64, totalStock int64) (int64, error) {
+	row := tx.QueryRow("INSERT INTO product_stock (product_id, added_by, added_date, quantity) VALUES (?, ?, datetime('now'), ?) RETURNING id", productId, userId, amount)
+
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		log.Printf("Error adding stock: %s", err)
+		return 0, err
+	}
+
+	err := writeAudit(tx, origin, "stock.add", "product_stock", strconv.FormatInt(id, 10), userId, map[string]any{
+		"product_id":  productId,
+		"total_stock": totalStock,
+	}, map[string]any{
+		"product_id":  productId,
+		"quantity":    amount,
+		"total_stock": totalStock + amount,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (m *SqliteMiddleware) stockAdded(origin models.Origin, id int64, product models.Product, userId string, amount int64) events.StockAdded {
+	return events.StockAdded{
+		StockID:     id,
+		ProductID:   product.ID,
+		ProductName: product.Name,
+		UserID:      userId,
+		Quantity:    amount,
+		TotalStock:  int64(product.TotalStock) + amount,
+		Origin:      origin,
+		Guild:       m.Guild,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// GetLatestTransactions returns every transaction on the leaderboard
+// described by filter, oldest first, with each user's running totals.
+func (m *SqliteMiddleware) GetLatestTransactions(filter models.LeaderboardFilter) (transactions []models.LatestTransaction, err error) {
+	var from int64
+	if !filter.From.IsZero() {
+		from = filter.From.Unix()
+	}
 
 	rows, err := m.Db.Query(`
 		SELECT
@@ -326,21 +743,30 @@ func (m *SqliteMiddleware) GetLatestTransactions() (transactions []models.Latest
             DATETIME(t.transaction_date),
             SUM(t.quantity) OVER (
                 PARTITION BY t.user_id
-                ORDER BY t.transaction_date
+                ORDER BY t.transaction_date, t.id
                 ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW
-            ) AS cumulative_transaction_count
+            ) AS cumulative_transaction_count,
+            SUM(t.quantity * t.price_paid) OVER (
+                PARTITION BY t.user_id
+                ORDER BY t.transaction_date, t.id
+                ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW
+            ) AS cumulative_spend
         FROM
             transactions t
+        JOIN products p ON
+            t.product_id = p.id
         LEFT JOIN users u ON
             t.user_id = u.id
         WHERE
-            t.transaction_date >= datetime('now', '-12 hour')  -- Adjust the period here
+            t.user_id IS NOT NULL
+            AND datetime(t.transaction_date) >= datetime($1, 'unixepoch')
+            AND ($2 = 0 OR t.product_id = $2)
+            AND ($3 = '' OR p.category = $3)
         ORDER BY
-            t.transaction_date ASC;
-		`)
+            t.transaction_date ASC, t.id ASC;
+		`, from, filter.ProductID, filter.Category)
 
 	if err != nil {
-		fmt.Printf("errro: %v\n", err)
 		return nil, err
 	}
 
@@ -352,95 +778,77 @@ func (m *SqliteMiddleware) GetLatestTransactions() (transactions []models.Latest
 			&transaction.UserName,
 			&transaction.TransactionDate,
 			&transaction.CumulativeTransactionCount,
+			&transaction.CumulativeSpend,
 		)
 
 		if err != nil {
-			fmt.Printf("errro: %v\n", err)
 			return nil, err
 		}
 
 		transactions = append(transactions, transaction)
 	}
 
-	return
+	return transactions, rows.Err()
 }
 
-func (m *SqliteMiddleware) GetTransactionLeaderboard() (leaderboard []models.TransactionLeaderboard, err error) {
+// GetTransactionLeaderboard ranks users by units bought or money spent
+// since filter.From, and by the same measure before filter.Compare to tell
+// how their rank has changed.
+func (m *SqliteMiddleware) GetTransactionLeaderboard(filter models.LeaderboardFilter) (leaderboard []models.TransactionLeaderboard, err error) {
+	var from int64
+	if !filter.From.IsZero() {
+		from = filter.From.Unix()
+	}
+
+	// Parameters are numbered in the order they first appear in the query.
 	rows, err := m.Db.Query(`
-	WITH total_quantities AS (
-		-- Calculate cumulative quantity sums over the last 12 hours
-		SELECT
-			t.user_id,
-			SUM(t.quantity) AS total_quantity_sum
-		FROM
-			transactions t
-		WHERE
-			t.transaction_date >= datetime('now', '-12 hour')  -- Last 12 hours
-		GROUP BY
-			t.user_id
-	),
-	recent_transactions AS (
-		-- Calculate cumulative quantity sums and ranks over the last 15 minutes
+	WITH totals AS (
 		SELECT
 			t.user_id,
-			SUM(t.quantity) AS recent_quantity_sum,
-			RANK() OVER (ORDER BY SUM(t.quantity) DESC) AS recent_rank
+			SUM(t.quantity) AS units,
+			SUM(t.quantity * t.price_paid) AS spend,
+			SUM(CASE WHEN datetime(t.transaction_date) < datetime($1, 'unixepoch') THEN t.quantity ELSE 0 END) AS previous_units,
+			SUM(CASE WHEN datetime(t.transaction_date) < datetime($1, 'unixepoch') THEN t.quantity * t.price_paid ELSE 0 END) AS previous_spend
 		FROM
 			transactions t
+		JOIN
+			products p ON t.product_id = p.id
 		WHERE
-			t.transaction_date >= datetime('now', '-15 minute')  -- Last 15 minutes
+			t.user_id IS NOT NULL
+			AND datetime(t.transaction_date) >= datetime($2, 'unixepoch')
+			AND ($3 = 0 OR t.product_id = $3)
+			AND ($4 = '' OR p.category = $4)
 		GROUP BY
 			t.user_id
 	),
-	previous_transactions AS (
-		-- Calculate cumulative quantity sums and ranks for the period before the last 15 minutes but within the last 12 hours
+	scores AS (
 		SELECT
-			t.user_id,
-			SUM(t.quantity) AS previous_quantity_sum,
-			RANK() OVER (ORDER BY SUM(t.quantity) DESC) AS previous_rank
+			user_id,
+			units,
+			spend,
+			CASE $5 WHEN 'spend' THEN spend ELSE units END AS score,
+			CASE $5 WHEN 'spend' THEN previous_spend ELSE previous_units END AS previous_score
 		FROM
-			transactions t
-		WHERE
-			t.transaction_date >= datetime('now', '-12 hour')  -- Last 12 hours
-			AND t.transaction_date < datetime('now', '-15 minute')  -- Before the last 15 minutes
-		GROUP BY
-			t.user_id
-	),
-	rank_changes AS (
-		-- Determine rank changes by comparing ranks in the last 15 minutes with the previous period
-		SELECT
-			r.user_id,
-			r.recent_rank,
-			p.previous_rank,
-			CASE
-				WHEN p.previous_rank IS NULL THEN 'New'  -- User is new in the leaderboard
-				WHEN r.recent_rank < p.previous_rank THEN '↑'  -- Rank increased
-				WHEN r.recent_rank > p.previous_rank THEN '↓'  -- Rank decreased
-				ELSE '='  -- Rank remained the same
-			END AS rank_change_indicator
-		FROM
-			recent_transactions r
-		LEFT JOIN
-			previous_transactions p ON r.user_id = p.user_id
+			totals
 	)
 	SELECT
-		tq.user_id,
-		u.name,
-		tq.total_quantity_sum,
-    	RANK() OVER (ORDER BY tq.total_quantity_sum DESC) AS current_rank,  -- Current rank based on last 12 hours
-		COALESCE(rc.rank_change_indicator, '=') AS rank_change_indicator
+		s.user_id,
+		COALESCE(u.name, ''),
+		s.units,
+		ROUND(s.spend, 2),
+		s.score,
+		RANK() OVER (ORDER BY s.score DESC) AS current_rank,
+		-- Users with nothing before the comparison time were not ranked then
+		CASE WHEN s.previous_score > 0 THEN RANK() OVER (ORDER BY s.previous_score DESC) ELSE 0 END
 	FROM
-		total_quantities tq
+		scores s
 	LEFT JOIN
-		rank_changes rc ON tq.user_id = rc.user_id
-	LEFT JOIN
-		users u ON tq.user_id = u.id
+		users u ON s.user_id = u.id
 	ORDER BY
-		tq.total_quantity_sum DESC;
-	`)
+		current_rank ASC, u.name ASC;
+	`, filter.Compare.Unix(), from, filter.ProductID, filter.Category, filter.Metric)
 
 	if err != nil {
-		fmt.Printf("errro: %v\n", err)
 		return
 	}
 
@@ -451,19 +859,24 @@ func (m *SqliteMiddleware) GetTransactionLeaderboard() (leaderboard []models.Tra
 			&transaction.UserID,
 			&transaction.UserName,
 			&transaction.TotalTransactionCount,
+			&transaction.TotalSpend,
+			&transaction.Score,
 			&transaction.CurrentRank,
-			&transaction.RankChangeIndicator,
+			&transaction.PreviousRank,
 		)
 
 		if err != nil {
-			fmt.Printf("errro: %v\n", err)
 			return
 		}
 
+		if transaction.PreviousRank > 0 {
+			transaction.RankChange = transaction.PreviousRank - transaction.CurrentRank
+		}
+
 		leaderboard = append(leaderboard, transaction)
 	}
 
-	return
+	return leaderboard, rows.Err()
 }
 
 func (m *SqliteMiddleware) GetUserUpcs() (upcs []models.Upc, err error) {
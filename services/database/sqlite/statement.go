@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"gostrecka/models"
+	"time"
+)
+
+// GetStatement returns the entries affecting the user's balance in the
+// period [from, to), priced the same way as the user_credits view.
+func (m *SqliteMiddleware) GetStatement(userId string, from time.Time, to time.Time) (statement models.Statement, err error) {
+	row := m.Db.QueryRow("SELECT id, name FROM users WHERE id = ?", userId)
+	if err = row.Scan(&statement.User.ID, &statement.User.Name); err != nil {
+		return
+	}
+
+	statement.From = from
+	statement.To = to
+
+	rows, err := m.Db.Query(`
+		SELECT date, kind, description, quantity, unit_price
+		FROM (
+			SELECT
+				DATETIME(t.transaction_date) AS date,
+				'strecka' AS kind,
+				p.name AS description,
+				t.quantity AS quantity,
+				-COALESCE(pp.internal_price, 0) AS unit_price
+			FROM
+				transactions t
+			JOIN
+				products p ON t.product_id = p.id
+			LEFT JOIN
+				product_price pp ON t.product_id = pp.product_id
+					AND t.transaction_date BETWEEN pp.start_date AND IFNULL(pp.end_date, DATETIME('now'))
+			WHERE
+				t.user_id = $1
+
+			UNION ALL
+
+			SELECT
+				DATETIME(ps.added_date),
+				'stock',
+				p.name,
+				ps.quantity,
+				COALESCE(pp.purchase_price, 0)
+			FROM
+				product_stock ps
+			JOIN
+				products p ON ps.product_id = p.id
+			LEFT JOIN
+				product_price pp ON ps.product_id = pp.product_id
+					AND ps.added_date BETWEEN pp.start_date AND IFNULL(pp.end_date, DATETIME('now'))
+			WHERE
+				ps.added_by = $1
+
+			UNION ALL
+
+			SELECT
+				DATETIME(up.payment_date),
+				'payment',
+				'',
+				1,
+				up.payment_amount
+			FROM
+				user_payments up
+			WHERE
+				up.user_id = $1
+		)
+		WHERE
+			datetime(date) < datetime($2, 'unixepoch')
+		ORDER BY
+			date ASC
+	`, userId, to.Unix())
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var entry models.StatementEntry
+		err = rows.Scan(
+			&entry.Date,
+			&entry.Kind,
+			&entry.Description,
+			&entry.Quantity,
+			&entry.UnitPrice,
+		)
+
+		if err != nil {
+			return
+		}
+
+		entry.Amount = float64(entry.Quantity) * entry.UnitPrice
+
+		if entry.Date.Before(from) {
+			statement.OpeningBalance += entry.Amount
+		} else {
+			statement.Entries = append(statement.Entries, entry)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return
+	}
+
+	statement.ClosingBalance = statement.OpeningBalance
+	for _, entry := range statement.Entries {
+		statement.ClosingBalance += entry.Amount
+	}
+
+	return
+}
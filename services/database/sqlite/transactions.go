@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"database/sql"
+	"gostrecka/models"
+	"gostrecka/services/events"
+	"strconv"
+	"time"
+)
+
+// GetTransaction returns a single transaction, or sql.ErrNoRows when it
+// does not exist or has been reversed.
+func (m *SqliteMiddleware) GetTransaction(transactionId int64) (transaction models.TransactionRecord, err error) {
+	err = m.Db.QueryRow(`
+		SELECT
+			t.id,
+			DATETIME(t.transaction_date),
+			COALESCE(t.user_id, ''),
+			COALESCE(u.name, ''),
+			t.product_id,
+			p.name,
+			t.quantity,
+			t.price_type,
+			t.price_paid
+		FROM
+			transactions t
+		LEFT JOIN
+			users u ON t.user_id = u.id
+		JOIN
+			products p ON t.product_id = p.id
+		WHERE
+			t.id = $1
+	`, transactionId).Scan(
+		&transaction.ID,
+		&transaction.TransactionDate,
+		&transaction.UserID,
+		&transaction.UserName,
+		&transaction.ProductID,
+		&transaction.ProductName,
+		&transaction.Quantity,
+		&transaction.PriceType,
+		&transaction.PricePaid,
+	)
+
+	return
+}
+
+// ReverseTransaction removes a transaction as if it never happened. The
+// audit log keeps what was removed. Reversing a transaction that is already
+// gone returns sql.ErrNoRows.
+func (m *SqliteMiddleware) ReverseTransaction(origin models.Origin, transactionId int64) error {
+	transaction, err := m.GetTransaction(transactionId)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.Db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// Two reversals racing each other both find the transaction above,
+	// only the one that deletes it succeeds.
+	result, err := tx.Exec("DELETE FROM transactions WHERE id = $1", transactionId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if removed, err := result.RowsAffected(); err != nil || removed == 0 {
+		tx.Rollback()
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		return err
+	}
+
+	err = writeAudit(tx, origin, "transaction.reverse", "transaction", strconv.FormatInt(transactionId, 10), transaction.UserID, map[string]any{
+		"product_id": transaction.ProductID,
+		"quantity":   transaction.Quantity,
+		"price_type": transaction.PriceType,
+		"price_paid": transaction.PricePaid,
+	}, nil)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	m.publish(events.TransactionReversed{
+		TransactionID: transaction.ID,
+		UserID:        transaction.UserID,
+		ProductID:     transaction.ProductID,
+		ProductName:   transaction.ProductName,
+		Quantity:      transaction.Quantity,
+		PriceType:     transaction.PriceType,
+		PricePaid:     transaction.PricePaid,
+		Origin:        origin,
+		Guild:         m.Guild,
+		CreatedAt:     time.Now(),
+	})
+
+	return nil
+}
+
+// DisputeTransaction records that the user a transaction was streckat on
+// does not recognise it. The transaction is left as it is for an admin to
+// reverse with /reverse.
+func (m *SqliteMiddleware) DisputeTransaction(origin models.Origin, transactionId int64) error {
+	transaction, err := m.GetTransaction(transactionId)
+	if err != nil {
+		return err
+	}
+
+	err = writeAudit(m.Db, origin, "transaction.dispute", "transaction", strconv.FormatInt(transactionId, 10), transaction.UserID, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	m.publish(events.TransactionDisputed{
+		TransactionID: transaction.ID,
+		UserID:        transaction.UserID,
+		ProductID:     transaction.ProductID,
+		ProductName:   transaction.ProductName,
+		Quantity:      transaction.Quantity,
+		PricePaid:     transaction.PricePaid,
+		Origin:        origin,
+		Guild:         m.Guild,
+		CreatedAt:     time.Now(),
+	})
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/zekrotja/ken"
+)
+
+var ErrAttachmentTooLarge = errors.New("attachment is too large")
+
+// DownloadAttachment fetches the attachment passed as the named option,
+// refusing files larger than maxSize bytes.
+func DownloadAttachment(ctx ken.Context, name string, maxSize int64) ([]byte, error) {
+	id := ctx.Options().GetByName(name).StringValue()
+
+	resolved := ctx.GetEvent().ApplicationCommandData().Resolved
+	if resolved == nil || resolved.Attachments[id] == nil {
+		return nil, fmt.Errorf("attachment %s not found", id)
+	}
+
+	attachment := resolved.Attachments[id]
+	if int64(attachment.Size) > maxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	client := http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(attachment.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download attachment: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	return data, nil
+}
@@ -14,7 +14,7 @@ import (
 func autocompleteInner(ctx *ken.AutocompleteContext, input string) ([]*discordgo.ApplicationCommandOptionChoice, error) {
 	input = strings.ToLower(input)
 
-	db := ctx.Get(static.DiDatabase).(database.Database)
+	db := ctx.Get(static.DiGuilds).(*database.Guilds).Get(ctx.Event().GuildID)
 	items, err := db.SearchProduct(input)
 
 	if err != nil {
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"fmt"
+	"gostrecka/models"
+	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+type AuditCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.SlashCommand     = (*AuditCommand)(nil)
+	_ discord.AdminCommand = (*AuditCommand)(nil)
+)
+
+func (c *AuditCommand) Name() string {
+	return "audit"
+}
+
+func (c *AuditCommand) Description() string {
+	return "Visar granskningsloggen över alla ändringar"
+}
+
+func (c *AuditCommand) Version() string {
+	return "1.0.0"
+}
+
+func (c *AuditCommand) IsAdminOnly() bool {
+	return true
+}
+
+func (c *AuditCommand) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
+func (c *AuditCommand) Options() []*discordgo.ApplicationCommandOption {
+	var limitMinValue float64 = 1.0
+
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Name:        "user",
+			Description: "Användaren som påverkades",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Name:        "actor",
+			Description: "Användaren som utförde ändringen",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "origin",
+			Description: "Var ändringen gjordes",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Discord", Value: models.OriginDiscord},
+				{Name: "Kiosk", Value: models.OriginKiosk},
+				{Name: "CLI", Value: models.OriginCli},
+				{Name: "API", Value: models.OriginApi},
+				{Name: "System", Value: models.OriginSystem},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "action",
+			Description: "Typ av ändring",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Strecka", Value: "transaction.create"},
+				{Name: "Ångrat streck", Value: "transaction.reverse"},
+				{Name: "Bestritt streck", Value: "transaction.dispute"},
+				{Name: "Lagersaldo", Value: "stock.add"},
+				{Name: "Betalning", Value: "payment.record"},
+				{Name: "Prisändring", Value: "product.price"},
+				{Name: "Ny produkt", Value: "product.create"},
+				{Name: "Produktkategori", Value: "product.category"},
+				{Name: "Produktnamn", Value: "product.rename"},
+				{Name: "Streckkod", Value: "product.upc"},
+				{Name: "Ny användare", Value: "user.create"},
+				{Name: "Inställningar", Value: "user.settings"},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "limit",
+			Description: "Antal rader att visa (max 25)",
+			Required:    false,
+			MinValue:    &limitMinValue,
+			MaxValue:    25,
+		},
+	}
+}
+
+func (c *AuditCommand) Run(ctx ken.Context) (err error) {
+	filter := models.AuditFilter{Limit: 15}
+	if userArg, ok := ctx.Options().GetByNameOptional("user"); ok {
+		filter.TargetUserID = userArg.UserValue(nil).ID
+	}
+	if actorArg, ok := ctx.Options().GetByNameOptional("actor"); ok {
+		filter.ActorID = actorArg.UserValue(nil).ID
+	}
+	if originArg, ok := ctx.Options().GetByNameOptional("origin"); ok {
+		filter.Source = originArg.StringValue()
+	}
+	if actionArg, ok := ctx.Options().GetByNameOptional("action"); ok {
+		filter.Action = actionArg.StringValue()
+	}
+	if limitArg, ok := ctx.Options().GetByNameOptional("limit"); ok {
+		filter.Limit = int(limitArg.IntValue())
+	}
+
+	db := discord.Database(ctx)
+	locale := discord.Locale(ctx)
+	entries, err := db.GetAuditLog(filter)
+	if err != nil {
+		log.Printf("error getting audit log: %v", err)
+		return ctx.RespondError(i18n.T(locale, "audit.failed"), i18n.T(locale, "error.title"))
+	}
+
+	if len(entries) == 0 {
+		return ctx.RespondEmbed(&discordgo.MessageEmbed{
+			Title:       i18n.T(locale, "audit.title"),
+			Description: i18n.T(locale, "audit.empty"),
+		})
+	}
+
+	var lines []string
+	for _, entry := range entries {
+		line := i18n.T(locale, "audit.entry", entry.ID, entry.CreatedAt.Format("2006-01-02 15:04"), entry.Action, entry.Source)
+		if entry.ActorID != "" {
+			line += " " + i18n.T(locale, "audit.by", "<@"+entry.ActorID+">")
+		}
+		if entry.TargetUserID != "" && entry.TargetUserID != entry.ActorID {
+			line += " " + i18n.T(locale, "audit.for", "<@"+entry.TargetUserID+">")
+		}
+		if entry.After != "" {
+			line += fmt.Sprintf("\n`%s`", entry.After)
+		}
+		lines = append(lines, line)
+	}
+
+	ctx.SetEphemeral(true)
+	return ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title:       i18n.T(locale, "audit.title"),
+		Description: truncate(strings.Join(lines, "\n"), 4096),
+	})
+}
+
+// truncate shortens s to at most max bytes, keeping whole lines.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	s = s[:max]
+	if i := strings.LastIndex(s, "\n"); i > 0 {
+		s = s[:i]
+	}
+
+	return s
+}
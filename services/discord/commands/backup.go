@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"gostrecka/internal/utils/static"
+	"gostrecka/services/backup"
+	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
+	"os"
+	"path/filepath"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+type BackupCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.SlashCommand     = (*BackupCommand)(nil)
+	_ discord.AdminCommand = (*BackupCommand)(nil)
+)
+
+func (c *BackupCommand) Name() string {
+	return "backup"
+}
+
+func (c *BackupCommand) Description() string {
+	return "Laddar upp den senaste säkerhetskopian av databasen"
+}
+
+func (c *BackupCommand) Version() string {
+	return "1.0.0"
+}
+
+func (c *BackupCommand) IsAdminOnly() bool {
+	return true
+}
+
+func (c *BackupCommand) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
+func (c *BackupCommand) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "new",
+			Description: "Ta en ny säkerhetskopia först",
+			Required:    false,
+		},
+	}
+}
+
+func (c *BackupCommand) Run(ctx ken.Context) (err error) {
+	ctx.SetEphemeral(true)
+
+	locale := discord.Locale(ctx)
+	if err = ctx.Defer(); err != nil {
+		return
+	}
+
+	service := ctx.Get(static.DiBackup).(*backup.BackupService)
+	guildId := ctx.GetEvent().GuildID
+
+	var path string
+	if newArg, ok := ctx.Options().GetByNameOptional("new"); ok && newArg.BoolValue() {
+		path, err = service.Snapshot(guildId)
+	} else {
+		path, err = service.Latest(guildId)
+	}
+
+	if err != nil {
+		return ctx.FollowUpError(i18n.T(locale, "backup.failed", err.Error()), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return ctx.FollowUpError(i18n.T(locale, "backup.read_failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+	defer file.Close()
+
+	return ctx.FollowUp(true, &discordgo.WebhookParams{
+		Content: i18n.T(locale, "backup.file", filepath.Base(path)),
+		Files: []*discordgo.File{
+			{
+				Name:        filepath.Base(path),
+				ContentType: "application/vnd.sqlite3",
+				Reader:      file,
+			},
+		},
+	}).Send().Error
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"fmt"
+	"gostrecka/services/achievements"
+	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+type BadgesCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.SlashCommand = (*BadgesCommand)(nil)
+	_ ken.DmCapable    = (*BadgesCommand)(nil)
+)
+
+func (c *BadgesCommand) Name() string {
+	return "badges"
+}
+
+func (c *BadgesCommand) Description() string {
+	return "Visar märkena en användare har låst upp"
+}
+
+func (c *BadgesCommand) Version() string {
+	return "1.0.0"
+}
+
+func (c *BadgesCommand) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Name:        "user",
+			Description: "Användaren att visa märkena för, du själv om den utelämnas",
+			Required:    false,
+		},
+	}
+}
+
+func (c *BadgesCommand) IsDmCapable() bool {
+	return true
+}
+
+func (c *BadgesCommand) Run(ctx ken.Context) (err error) {
+	selectedUser := ctx.User()
+	if userArg, ok := ctx.Options().GetByNameOptional("user"); ok {
+		selectedUser = userArg.UserValue(ctx)
+	}
+
+	db := discord.Database(ctx)
+	locale := discord.Locale(ctx)
+
+	user, _, err := db.GetUser(selectedUser.ID)
+	if err != nil {
+		return ctx.RespondError(i18n.T(locale, "error.user_not_found"), i18n.T(locale, "error.title"))
+	}
+
+	badges, err := db.GetBadges(user.ID)
+	if err != nil {
+		log.Printf("error getting badges: %v", err)
+		return ctx.RespondError(i18n.T(locale, "badges.failed"), i18n.T(locale, "error.title"))
+	}
+
+	unlocked := make(map[string]string, len(badges))
+	for _, badge := range badges {
+		unlocked[badge.Badge] = fmt.Sprintf("<t:%d:d>", badge.UnlockedAt.Unix())
+	}
+
+	// Unlocked badges first, in the order they were unlocked in.
+	var lines, locked []string
+	for _, badge := range badges {
+		if achievement, ok := achievements.Find(badge.Badge); ok {
+			lines = append(lines, fmt.Sprintf("%s **%s** %s\n%s", achievement.Emoji, achievement.Name(locale),
+				unlocked[badge.Badge], achievement.Description(locale)))
+		}
+	}
+	for _, achievement := range achievements.Achievements {
+		if _, ok := unlocked[achievement.ID]; !ok {
+			locked = append(locked, fmt.Sprintf("🔒 %s\n%s", achievement.Name(locale), achievement.Description(locale)))
+		}
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       i18n.T(locale, "badges.title", user.Name),
+		Description: strings.Join(lines, "\n\n"),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: i18n.T(locale, "badges.footer", len(achievements.Achievements)-len(locked), len(achievements.Achievements)),
+		},
+	}
+
+	if len(locked) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  i18n.T(locale, "badges.locked"),
+			Value: strings.Join(locked, "\n\n"),
+		})
+	}
+
+	return ctx.RespondEmbed(embed)
+}
@@ -1,15 +1,22 @@
 package commands
 
 import (
+	"bytes"
 	"fmt"
 	"gostrecka/internal/utils/static"
-	"gostrecka/services/database"
+	"gostrecka/services/discord"
+	"gostrecka/services/env"
+	"gostrecka/services/i18n"
+	"gostrecka/utils"
+	"log"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/zekrotja/ken"
 )
 
-type BalanceCommand struct{}
+type BalanceCommand struct {
+	discord.GuildScope
+}
 
 var (
 	_ ken.SlashCommand = (*BalanceCommand)(nil)
@@ -48,41 +55,74 @@ func (c *BalanceCommand) Run(ctx ken.Context) (err error) {
 		selectedUser = userArg.UserValue(ctx)
 	}
 
-	db := ctx.Get(static.DiDatabase).(database.Database)
+	return respondBalance(ctx, selectedUser)
+}
+
+// respondBalance responds with the balance of selectedUser.
+func respondBalance(ctx ken.Context, selectedUser *discordgo.User) (err error) {
+	db := discord.Database(ctx)
+	locale := discord.Locale(ctx)
 
 	user, balance, err := db.GetUser(selectedUser.ID)
 
 	if err != nil {
-		ctx.FollowUpError("Användaren finns inte", "")
+		return ctx.RespondError(i18n.T(locale, "error.user_not_found"), i18n.T(locale, "error.title"))
 	}
 
 	var total string
 	if balance.TotalCreditsEarned > balance.TotalDebtIncurred {
-		total = fmt.Sprintf("%.02fkr i kredit", balance.TotalCreditsEarned-balance.TotalDebtIncurred)
+		total = i18n.T(locale, "balance.credit", balance.TotalCreditsEarned-balance.TotalDebtIncurred)
 	} else {
-		total = fmt.Sprintf("%.02fkr i skuld", balance.TotalDebtIncurred-balance.TotalCreditsEarned)
+		total = i18n.T(locale, "balance.debt", balance.TotalDebtIncurred-balance.TotalCreditsEarned)
 	}
 
-	err = ctx.RespondEmbed(&discordgo.MessageEmbed{
-		Title:       "Saldo",
-		Description: fmt.Sprintf("Saldo för <@%s>", user.ID),
+	embed := &discordgo.MessageEmbed{
+		Title:       i18n.T(locale, "balance.title"),
+		Description: i18n.T(locale, "balance.description", fmt.Sprintf("<@%s>", user.ID)),
 		Fields: []*discordgo.MessageEmbedField{
 			{
-				Name:  "Nuvarande",
+				Name:  i18n.T(locale, "balance.current"),
 				Value: total,
 			},
 			{
-				Name:   "Total skuld",
+				Name:   i18n.T(locale, "balance.total_debt"),
 				Value:  fmt.Sprintf("%.02fkr", balance.TotalDebtIncurred),
 				Inline: true,
 			},
 			{
-				Name:   "Totalt saldo",
+				Name:   i18n.T(locale, "balance.total_credit"),
 				Value:  fmt.Sprintf("%.02fkr", balance.TotalCreditsEarned),
 				Inline: true,
 			},
 		},
-	})
+	}
 
-	return
+	var files []*discordgo.File
+
+	swish := ctx.Get(static.DiConfig).(env.Config).SwishOf(ctx.GetEvent().GuildID)
+	if swish.Enabled() && balance.DebtIncurred > 0 {
+		qr, err := utils.GenerateSwishQR(swish.Payee, balance.DebtIncurred, swish.Reference(user.ID), 256)
+		if err != nil {
+			log.Printf("error generating swish qr: %v", err)
+		} else {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:  i18n.T(locale, "balance.swish_title"),
+				Value: i18n.T(locale, "balance.swish", balance.DebtIncurred, swish.Payee, swish.Reference(user.ID)),
+			})
+			embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://swish.png"}
+			files = append(files, &discordgo.File{
+				Name:        "swish.png",
+				ContentType: "image/png",
+				Reader:      bytes.NewReader(qr),
+			})
+		}
+	}
+
+	return ctx.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Files:  files,
+		},
+	})
 }
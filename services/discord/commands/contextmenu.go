@@ -0,0 +1,405 @@
+package commands
+
+import (
+	"gostrecka/models"
+	"gostrecka/services/database"
+	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+const (
+	// productPickerTimeout is how long the product picker keeps working.
+	productPickerTimeout = 14 * time.Minute
+	// productPickerOptions is the most options Discord shows in a select menu.
+	productPickerOptions = 25
+)
+
+// StreckaUserCommand is the "Strecka åt…" entry in the context menu of a
+// user.
+type StreckaUserCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.UserCommand = (*StreckaUserCommand)(nil)
+)
+
+func (c *StreckaUserCommand) TypeUser() {}
+
+func (c *StreckaUserCommand) Name() string {
+	return "Strecka åt…"
+}
+
+func (c *StreckaUserCommand) Description() string {
+	return "Streckar en produkt åt användaren"
+}
+
+func (c *StreckaUserCommand) Run(ctx ken.Context) (err error) {
+	return newProductPicker(ctx, targetUser(ctx)).start(ctx)
+}
+
+// StreckaMessageCommand is the "Strecka åt avsändaren" entry in the context
+// menu of a message.
+type StreckaMessageCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.MessageCommand = (*StreckaMessageCommand)(nil)
+)
+
+func (c *StreckaMessageCommand) TypeMessage() {}
+
+func (c *StreckaMessageCommand) Name() string {
+	return "Strecka åt avsändaren"
+}
+
+func (c *StreckaMessageCommand) Description() string {
+	return "Streckar en produkt åt den som skrev meddelandet"
+}
+
+func (c *StreckaMessageCommand) Run(ctx ken.Context) (err error) {
+	data := ctx.GetEvent().ApplicationCommandData()
+	message, ok := data.Resolved.Messages[data.TargetID]
+	if !ok || message.Author == nil {
+		locale := discord.Locale(ctx)
+		ctx.SetEphemeral(true)
+		return ctx.RespondError(i18n.T(locale, "picker.no_message"), i18n.T(locale, "error.title"))
+	}
+
+	return newProductPicker(ctx, message.Author).start(ctx)
+}
+
+// BalanceUserCommand is the "Visa saldo" entry in the context menu of a
+// user.
+type BalanceUserCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.UserCommand = (*BalanceUserCommand)(nil)
+)
+
+func (c *BalanceUserCommand) TypeUser() {}
+
+func (c *BalanceUserCommand) Name() string {
+	return "Visa saldo"
+}
+
+func (c *BalanceUserCommand) Description() string {
+	return "Visar användarens saldo"
+}
+
+func (c *BalanceUserCommand) Run(ctx ken.Context) (err error) {
+	ctx.SetEphemeral(true)
+	return respondBalance(ctx, targetUser(ctx))
+}
+
+// HistoryUserCommand is the "Visa historik" entry in the context menu of a
+// user.
+type HistoryUserCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.UserCommand = (*HistoryUserCommand)(nil)
+)
+
+func (c *HistoryUserCommand) TypeUser() {}
+
+func (c *HistoryUserCommand) Name() string {
+	return "Visa historik"
+}
+
+func (c *HistoryUserCommand) Description() string {
+	return "Visar vad användaren har streckat"
+}
+
+func (c *HistoryUserCommand) Run(ctx ken.Context) (err error) {
+	discordUser := targetUser(ctx)
+	if discordUser.ID != ctx.User().ID && !discord.IsAdmin(ctx) {
+		locale := discord.Locale(ctx)
+		ctx.SetEphemeral(true)
+		return ctx.RespondError(i18n.T(locale, "error.forbidden"), i18n.T(locale, "error.title"))
+	}
+
+	return respondHistory(ctx, discordUser, models.TransactionFilter{UserID: discordUser.ID, Limit: historyPageSize})
+}
+
+// targetUser returns the user a user command was invoked on.
+func targetUser(ctx ken.Context) *discordgo.User {
+	data := ctx.GetEvent().ApplicationCommandData()
+	return data.Resolved.Users[data.TargetID]
+}
+
+// productPicker lets the invoking user pick a product to strecka on target
+// from a select menu. The menu holds the first products in stock, a search
+// narrows it down when there are more than fit.
+type productPicker struct {
+	mu sync.Mutex
+
+	db          database.Database
+	streckare   streckare
+	session     *discordgo.Session
+	interaction *discordgo.Interaction
+	// locale is the invoker's language, which the picker is written in.
+	locale i18n.Locale
+
+	actorID string
+	target  *discordgo.User
+	query   string
+	done    bool
+}
+
+func newProductPicker(ctx ken.Context, target *discordgo.User) *productPicker {
+	return &productPicker{
+		db:          discord.Database(ctx),
+		streckare:   newStreckare(ctx),
+		session:     ctx.GetSession(),
+		interaction: ctx.GetEvent().Interaction,
+		locale:      discord.Locale(ctx),
+		actorID:     ctx.User().ID,
+		target:      target,
+	}
+}
+
+func (p *productPicker) id(name string) string {
+	return "product-picker-" + name + "-" + p.interaction.ID
+}
+
+// start responds with the picker and starts handling it.
+func (p *productPicker) start(ctx ken.Context) error {
+	ctx.SetEphemeral(true)
+
+	if p.target.Bot {
+		return ctx.RespondError(i18n.T(p.locale, "picker.bot"), i18n.T(p.locale, "error.title"))
+	}
+	if _, _, err := p.db.GetUser(p.target.ID); err != nil {
+		return ctx.RespondError(i18n.T(p.locale, "error.not_registered", p.target.Mention()), i18n.T(p.locale, "error.title"))
+	}
+
+	data, err := p.message()
+	if err != nil {
+		log.Printf("error listing products: %v", err)
+		return ctx.RespondError(i18n.T(p.locale, "picker.failed"), i18n.T(p.locale, "error.title"))
+	}
+
+	err = ctx.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+	if err != nil {
+		return err
+	}
+
+	components := ctx.GetKen().Components()
+	components.Register(p.id("select"), p.selected)
+	components.Register(p.id("search"), p.search)
+	time.AfterFunc(productPickerTimeout, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		p.done = true
+		components.Unregister(p.id("select"), p.id("search"))
+	})
+
+	return nil
+}
+
+// message returns the picker with the products matching the current query.
+func (p *productPicker) message() (*discordgo.InteractionResponseData, error) {
+	items, err := p.db.SearchProduct(p.query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Products in stock first, otherwise in the order they were found.
+	slices.SortStableFunc(items, func(a, b models.ProductWithPrice) int {
+		return btoi(b.Product.TotalStock > 0) - btoi(a.Product.TotalStock > 0)
+	})
+
+	content := i18n.T(p.locale, "picker.prompt", p.target.Mention())
+	if p.query != "" {
+		content = i18n.T(p.locale, "picker.query", content, p.query)
+	}
+	if len(items) > productPickerOptions {
+		content += "\n" + i18n.T(p.locale, "picker.truncated", productPickerOptions, len(items))
+		items = items[:productPickerOptions]
+	}
+
+	search := discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{CustomID: p.id("search"), Label: i18n.T(p.locale, "picker.search"), Style: discordgo.SecondaryButton},
+		},
+	}
+
+	if len(items) == 0 {
+		content = i18n.T(p.locale, "picker.empty")
+		if p.query != "" {
+			content = i18n.T(p.locale, "picker.no_match", p.query)
+		}
+
+		return &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: []discordgo.MessageComponent{search},
+		}, nil
+	}
+
+	options := make([]discordgo.SelectMenuOption, 0, len(items))
+	for _, item := range items {
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       item.Product.Name,
+			Value:       strconv.FormatInt(item.Product.ID, 10),
+			Description: i18n.T(p.locale, "picker.option", item.Price.InternalPrice, item.Product.TotalStock),
+		})
+	}
+
+	return &discordgo.InteractionResponseData{
+		Content: content,
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.SelectMenu{
+						CustomID:    p.id("select"),
+						Placeholder: i18n.T(p.locale, "picker.placeholder"),
+						Options:     options,
+					},
+				},
+			},
+			search,
+		},
+	}, nil
+}
+
+func (p *productPicker) allowed(ctx ken.ComponentContext) bool {
+	if ctx.User().ID == p.actorID {
+		return true
+	}
+
+	locale := discord.InteractionLocale(p.db, p.streckare.config, ctx.GetEvent())
+	ctx.SetEphemeral(true)
+	ctx.RespondError(i18n.T(locale, "picker.not_yours"), i18n.T(locale, "error.title"))
+	return false
+}
+
+// selected streckar the picked product and closes the picker.
+func (p *productPicker) selected(ctx ken.ComponentContext) bool {
+	if !p.allowed(ctx) {
+		return false
+	}
+
+	if !p.mu.TryLock() {
+		ctx.Respond(&discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+		return false
+	}
+	defer p.mu.Unlock()
+
+	if p.done {
+		ctx.Respond(&discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+		return false
+	}
+
+	values := ctx.GetData().Values
+	if len(values) != 1 {
+		return false
+	}
+
+	productID, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		log.Printf("error converting product Id to int64: %v", err)
+		return false
+	}
+
+	// The receipt is posted publicly as the response to the selection, the
+	// picker only its invoker sees is closed afterwards.
+	if err = p.streckare.strecka(ctx, p.target, productID, 1); err != nil {
+		log.Printf("error strecka from product picker: %v", err)
+		return false
+	}
+
+	p.done = true
+	content := i18n.T(p.locale, "picker.done", p.target.Mention())
+	components := []discordgo.MessageComponent{}
+	_, err = p.session.InteractionResponseEdit(p.interaction, &discordgo.WebhookEdit{
+		Content:    &content,
+		Components: &components,
+	})
+	if err != nil {
+		log.Printf("error closing product picker: %v", err)
+	}
+
+	return true
+}
+
+// search asks for a query and shows the products matching it.
+func (p *productPicker) search(ctx ken.ComponentContext) bool {
+	if !p.allowed(ctx) {
+		return false
+	}
+
+	submitted, err := ctx.OpenModal(i18n.T(p.locale, "picker.search_title"), "", func(b ken.ComponentAssembler) {
+		b.AddActionsRow(func(b ken.ComponentAssembler) {
+			b.Add(discordgo.TextInput{
+				CustomID: "query",
+				Label:    i18n.T(p.locale, "picker.search_label"),
+				Style:    discordgo.TextInputShort,
+				Required: false,
+				Value:    p.query,
+			}, nil)
+		})
+	})
+	if err != nil {
+		log.Printf("error opening product search: %v", err)
+		return false
+	}
+
+	var mctx ken.ModalContext
+	select {
+	case mctx = <-submitted:
+	case <-time.After(productPickerTimeout):
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done {
+		mctx.Respond(&discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+		return false
+	}
+
+	p.query = strings.TrimSpace(mctx.GetComponentByID("query").GetValue())
+	data, err := p.message()
+	if err != nil {
+		log.Printf("error listing products: %v", err)
+		return false
+	}
+
+	err = mctx.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: data,
+	})
+	if err != nil {
+		log.Printf("error updating product picker: %v", err)
+		return false
+	}
+
+	return true
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"gostrecka/internal/utils/static"
+	"gostrecka/services/discord"
+	"gostrecka/services/env"
+	"gostrecka/services/export"
+	"gostrecka/services/i18n"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+type ExportCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.SlashCommand     = (*ExportCommand)(nil)
+	_ discord.AdminCommand = (*ExportCommand)(nil)
+)
+
+func (c *ExportCommand) Name() string {
+	return "export"
+}
+
+func (c *ExportCommand) Description() string {
+	return "Exporterar data som CSV, JSON eller SIE"
+}
+
+func (c *ExportCommand) Version() string {
+	return "1.0.0"
+}
+
+func (c *ExportCommand) IsAdminOnly() bool {
+	return true
+}
+
+func (c *ExportCommand) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
+func (c *ExportCommand) Options() []*discordgo.ApplicationCommandOption {
+	format := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "format",
+		Description: "Filformat, förvalt är CSV",
+		Required:    false,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{Name: "CSV", Value: export.FormatCsv},
+			{Name: "JSON", Value: export.FormatJson},
+		},
+	}
+
+	from := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "from",
+		Description: "Första dagen i formatet ÅÅÅÅ-MM-DD, förvalt är början av månaden",
+		Required:    false,
+	}
+	to := &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "to",
+		Description: "Sista dagen i formatet ÅÅÅÅ-MM-DD, förvalt är idag",
+		Required:    false,
+	}
+
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "transactions",
+			Description: "Exporterar alla streck under en period",
+			Options:     []*discordgo.ApplicationCommandOption{from, to, format},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "users",
+			Description: "Exporterar alla användare med saldon",
+			Options:     []*discordgo.ApplicationCommandOption{format},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "products",
+			Description: "Exporterar alla produkter med prishistorik",
+			Options:     []*discordgo.ApplicationCommandOption{format},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "sie",
+			Description: "Exporterar bokföringen för en period som SIE4-fil",
+			Options:     []*discordgo.ApplicationCommandOption{from, to},
+		},
+	}
+}
+
+func (c *ExportCommand) Run(ctx ken.Context) (err error) {
+	ctx.SetEphemeral(true)
+	if err = ctx.Defer(); err != nil {
+		return
+	}
+
+	return ctx.HandleSubCommands(
+		ken.SubCommandHandler{Name: "transactions", Run: c.transactions},
+		ken.SubCommandHandler{Name: "users", Run: c.users},
+		ken.SubCommandHandler{Name: "products", Run: c.products},
+		ken.SubCommandHandler{Name: "sie", Run: c.sie},
+	)
+}
+
+func (c *ExportCommand) transactions(ctx ken.SubCommandContext) (err error) {
+	locale := discord.Locale(ctx)
+	from, to, err := period(ctx)
+	if err != nil {
+		return ctx.FollowUpError(i18n.T(locale, "error.invalid_date"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	db := discord.Database(ctx)
+	transactions, err := db.GetTransactions(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("error getting transactions: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "export.transactions_failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	name := fmt.Sprintf("streck-%s-%s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	return c.send(ctx, name, func(buf *bytes.Buffer, format string) error {
+		return export.Transactions(buf, format, transactions)
+	})
+}
+
+func (c *ExportCommand) sie(ctx ken.SubCommandContext) (err error) {
+	locale := discord.Locale(ctx)
+	from, to, err := period(ctx)
+	if err != nil {
+		return ctx.FollowUpError(i18n.T(locale, "error.invalid_date"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	db := discord.Database(ctx)
+	entries, err := db.GetLedger(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("error getting ledger: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "export.ledger_failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	var buf bytes.Buffer
+	config := ctx.Get(static.DiConfig).(env.Config).SieOf(ctx.GetEvent().GuildID)
+	if err = export.Sie(&buf, config, from, to.AddDate(0, 0, 1), entries); err != nil {
+		log.Printf("error encoding SIE export: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "export.failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	return ctx.FollowUp(true, &discordgo.WebhookParams{
+		Content: i18n.T(locale, "export.sie", from.Format("2006-01-02"), to.Format("2006-01-02")),
+		Files: []*discordgo.File{
+			{
+				Name:        fmt.Sprintf("bokforing-%s-%s.se", from.Format("2006-01-02"), to.Format("2006-01-02")),
+				ContentType: "text/plain",
+				Reader:      &buf,
+			},
+		},
+	}).Send().Error
+}
+
+func (c *ExportCommand) users(ctx ken.SubCommandContext) (err error) {
+	locale := discord.Locale(ctx)
+	db := discord.Database(ctx)
+	users, err := db.GetUsers()
+	if err != nil {
+		log.Printf("error getting users: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "export.users_failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	return c.send(ctx, "anvandare-"+time.Now().Format("2006-01-02"), func(buf *bytes.Buffer, format string) error {
+		return export.Users(buf, format, users)
+	})
+}
+
+func (c *ExportCommand) products(ctx ken.SubCommandContext) (err error) {
+	locale := discord.Locale(ctx)
+	db := discord.Database(ctx)
+	prices, err := db.GetPriceHistory()
+	if err != nil {
+		log.Printf("error getting price history: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "export.products_failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	return c.send(ctx, "produkter-"+time.Now().Format("2006-01-02"), func(buf *bytes.Buffer, format string) error {
+		return export.Products(buf, format, prices)
+	})
+}
+
+// send encodes the export in the requested format and uploads it.
+func (c *ExportCommand) send(ctx ken.SubCommandContext, name string, encode func(buf *bytes.Buffer, format string) error) error {
+	format := export.FormatCsv
+	if formatArg, ok := ctx.Options().GetByNameOptional("format"); ok {
+		format = formatArg.StringValue()
+	}
+
+	var buf bytes.Buffer
+	if err := encode(&buf, format); err != nil {
+		log.Printf("error encoding export: %v", err)
+		locale := discord.Locale(ctx)
+		return ctx.FollowUpError(i18n.T(locale, "export.failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	contentType := "text/csv"
+	if format == export.FormatJson {
+		contentType = "application/json"
+	}
+
+	return ctx.FollowUp(true, &discordgo.WebhookParams{
+		Files: []*discordgo.File{
+			{
+				Name:        name + "." + format,
+				ContentType: contentType,
+				Reader:      &buf,
+			},
+		},
+	}).Send().Error
+}
+
+// period returns the first and last day given by the from and to options,
+// defaulting to the current month so far.
+func period(ctx ken.Context) (from time.Time, to time.Time, err error) {
+	now := time.Now()
+	from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	if fromArg, ok := ctx.Options().GetByNameOptional("from"); ok {
+		if from, err = time.ParseInLocation("2006-01-02", fromArg.StringValue(), time.Local); err != nil {
+			return
+		}
+	}
+	if toArg, ok := ctx.Options().GetByNameOptional("to"); ok {
+		if to, err = time.ParseInLocation("2006-01-02", toArg.StringValue(), time.Local); err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -2,18 +2,25 @@ package commands
 
 import (
 	"fmt"
+	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
 	"os"
 	"runtime"
+	"slices"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/zekrotja/ken"
 )
 
-type HelpCommand struct{}
+type HelpCommand struct {
+	discord.GuildScope
+}
 
 var (
-	_ ken.SlashCommand = (*HelpCommand)(nil)
-	_ ken.DmCapable    = (*HelpCommand)(nil)
+	_ ken.SlashCommand        = (*HelpCommand)(nil)
+	_ ken.DmCapable           = (*HelpCommand)(nil)
+	_ ken.AutocompleteCommand = (*HelpCommand)(nil)
 )
 
 func (c *HelpCommand) Name() string {
@@ -21,7 +28,7 @@ func (c *HelpCommand) Name() string {
 }
 
 func (c *HelpCommand) Description() string {
-	return "Basic Test Command"
+	return "Visar kommandona"
 }
 
 func (c *HelpCommand) Version() string {
@@ -33,47 +40,225 @@ func (c *HelpCommand) Type() discordgo.ApplicationCommandType {
 }
 
 func (c *HelpCommand) Options() []*discordgo.ApplicationCommandOption {
-	return []*discordgo.ApplicationCommandOption{}
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "command",
+			Description:  "Kommandot att visa mer om",
+			Required:     false,
+			Autocomplete: true,
+		},
+	}
 }
 
 func (c *HelpCommand) IsDmCapable() bool {
 	return true
 }
 
+func (c *HelpCommand) Autocomplete(ctx *ken.AutocompleteContext) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	input, _ := ctx.GetInput("command")
+	input = strings.ToLower(strings.TrimPrefix(input, "/"))
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, info := range slashCommands(ctx.GetKen()) {
+		name := info.ApplicationCommand.Name
+		if strings.Contains(name, input) && len(choices) < 25 {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: "/" + name, Value: name})
+		}
+	}
+
+	return choices, nil
+}
+
 func (c *HelpCommand) Run(ctx ken.Context) (err error) {
-	hostname, _ := os.Hostname()
-	var embed = &discordgo.MessageEmbed{
-		Title:       "Hjälp",
-		Description: "Jag är dum och kommer inte ihåg vad jag ska göra <:(",
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:  "Vart körs botten?",
-				Value: fmt.Sprintf("På %s %s (v%s)", runtime.GOOS, hostname, runtime.Version()),
-			},
-			{
-				Name:   "/user create [user]",
-				Value:  "Registrerar ditt (eller någon annans) konto i systemet",
-				Inline: true,
-			},
-			{
-				Name:   "/balance [user]",
-				Value:  "Visar hur mycket du (eller någon annan) har köpt för",
-				Inline: false,
-			},
-			{
-				Name:   "/strecka <product> [amount] [user]",
-				Value:  "Streckar en produkt åt dig (eller någon annan)",
-				Inline: false,
-			},
-		},
+	ctx.SetEphemeral(true)
+
+	locale := discord.Locale(ctx)
+	if commandArg, ok := ctx.Options().GetByNameOptional("command"); ok {
+		name := strings.TrimPrefix(strings.TrimSpace(commandArg.StringValue()), "/")
+		for _, info := range slashCommands(ctx.GetKen()) {
+			if info.ApplicationCommand.Name == name {
+				return ctx.RespondEmbed(helpDetails(locale, info))
+			}
+		}
+
+		return ctx.RespondError(i18n.T(locale, "help.unknown", name), i18n.T(locale, "error.title"))
 	}
 
-	ctx.Respond(&discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-		},
+	return ctx.RespondEmbed(helpOverview(locale, ctx.GetKen().GetCommandInfo(), discord.IsAdmin(ctx)))
+}
+
+// slashCommands returns the registered slash commands sorted by name.
+func slashCommands(k *ken.Ken) []*ken.CommandInfo {
+	var commands []*ken.CommandInfo
+	for _, info := range k.GetCommandInfo() {
+		if info.ApplicationCommand.Type == discordgo.ChatApplicationCommand {
+			commands = append(commands, info)
+		}
+	}
+
+	slices.SortFunc(commands, func(a, b *ken.CommandInfo) int {
+		return strings.Compare(a.ApplicationCommand.Name, b.ApplicationCommand.Name)
 	})
 
-	return
+	return commands
+}
+
+// helpOverview lists every command with its description, admin commands
+// only to admins.
+func helpOverview(locale i18n.Locale, infos ken.CommandInfoList, admin bool) *discordgo.MessageEmbed {
+	var commands, adminCommands []string
+	menus := map[discordgo.ApplicationCommandType][]string{}
+
+	for _, info := range infos {
+		cmd := info.ApplicationCommand
+		if cmd.Type != discordgo.ChatApplicationCommand {
+			menus[cmd.Type] = append(menus[cmd.Type], helpText(locale, "command_name."+cmd.Name, cmd.Name))
+			continue
+		}
+
+		line := fmt.Sprintf("`/%s` %s", cmd.Name, helpText(locale, "command."+cmd.Name, cmd.Description))
+		if implements(info, "IsAdminOnly") {
+			adminCommands = append(adminCommands, line)
+		} else {
+			commands = append(commands, line)
+		}
+	}
+
+	slices.Sort(commands)
+	slices.Sort(adminCommands)
+
+	embed := &discordgo.MessageEmbed{
+		Title: i18n.T(locale, "help.title"),
+		// The description holds more text than a field, which the commands
+		// would outgrow.
+		Description: i18n.T(locale, "help.details") + "\n\n" + strings.Join(commands, "\n"),
+	}
+
+	if admin && len(adminCommands) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  i18n.T(locale, "help.admin_commands"),
+			Value: strings.Join(adminCommands, "\n"),
+		})
+	}
+
+	for _, menu := range []struct {
+		typ discordgo.ApplicationCommandType
+		key string
+	}{
+		{discordgo.UserApplicationCommand, "help.user_menu"},
+		{discordgo.MessageApplicationCommand, "help.message_menu"},
+	} {
+		if names := menus[menu.typ]; len(names) > 0 {
+			slices.Sort(names)
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:  i18n.T(locale, menu.key),
+				Value: strings.Join(names, ", "),
+			})
+		}
+	}
+
+	hostname, _ := os.Hostname()
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: i18n.T(locale, "help.host", runtime.GOOS, hostname, runtime.Version()),
+	}
+
+	return embed
+}
+
+// helpDetails shows how a command is used, with every subcommand and
+// option.
+func helpDetails(locale i18n.Locale, info *ken.CommandInfo) *discordgo.MessageEmbed {
+	cmd := info.ApplicationCommand
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "/" + cmd.Name,
+		Description: helpText(locale, "command."+cmd.Name, cmd.Description),
+		Footer:      &discordgo.MessageEmbedFooter{Text: i18n.T(locale, "help.notation")},
+	}
+
+	var notes []string
+	if implements(info, "IsAdminOnly") {
+		notes = append(notes, i18n.T(locale, "help.admin_only"))
+	}
+	if implements(info, "IsDmCapable") {
+		notes = append(notes, i18n.T(locale, "help.dm_capable"))
+	}
+	if len(notes) > 0 {
+		embed.Description += "\n\n" + strings.Join(notes, "\n")
+	}
+
+	var subcommands []*discordgo.ApplicationCommandOption
+	for _, option := range cmd.Options {
+		if option.Type == discordgo.ApplicationCommandOptionSubCommand {
+			subcommands = append(subcommands, option)
+		}
+	}
+
+	if len(subcommands) == 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  i18n.T(locale, "help.usage"),
+			Value: helpUsage(locale, "command."+cmd.Name, "/"+cmd.Name, cmd.Options),
+		})
+
+		return embed
+	}
+
+	for _, subcommand := range subcommands {
+		key := "command." + cmd.Name + "." + subcommand.Name
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("/%s %s", cmd.Name, subcommand.Name),
+			Value: helpText(locale, key, subcommand.Description) + "\n" + helpUsage(locale, key, fmt.Sprintf("/%s %s", cmd.Name, subcommand.Name), subcommand.Options),
+		})
+	}
+
+	return embed
+}
+
+// helpUsage returns the usage line of a command followed by a line per
+// option, with required options in <> and optional ones in [].
+func helpUsage(locale i18n.Locale, key string, usage string, options []*discordgo.ApplicationCommandOption) string {
+	lines := []string{""}
+	for _, option := range options {
+		argument := "[" + option.Name + "]"
+		if option.Required {
+			argument = "<" + option.Name + ">"
+		}
+		usage += " " + argument
+
+		line := fmt.Sprintf("`%s` %s", argument, helpText(locale, key+"."+option.Name, option.Description))
+		if len(option.Choices) > 0 {
+			var choices []string
+			for _, choice := range option.Choices {
+				choices = append(choices, helpText(locale, fmt.Sprintf("%s.%s=%v", key, option.Name, choice.Value), choice.Name))
+			}
+			line += fmt.Sprintf(" (%s)", strings.Join(choices, ", "))
+		}
+		lines = append(lines, line)
+	}
+
+	lines[0] = "`" + usage + "`"
+	return strings.Join(lines, "\n")
+}
+
+// helpText returns the catalogue's translation of a command's name or
+// description, or the text the command itself has.
+func helpText(locale i18n.Locale, key string, text string) string {
+	if message, ok := i18n.Lookup(locale, key); ok {
+		return message
+	}
+
+	return text
+}
+
+// implements reports whether the command has a method that takes no
+// arguments and returns true, such as IsAdminOnly or IsDmCapable.
+func implements(info *ken.CommandInfo, method string) bool {
+	values := info.Implementations[method]
+	if len(values) != 1 {
+		return false
+	}
+
+	value, _ := values[0].(bool)
+	return value
 }
@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"fmt"
+	"gostrecka/models"
+	"gostrecka/services/database"
+	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+const (
+	historyPageSize = 10
+	// historyTimeout is how long the page buttons keep working.
+	historyTimeout = 15 * time.Minute
+)
+
+type HistoryCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.SlashCommand = (*HistoryCommand)(nil)
+)
+
+func (c *HistoryCommand) Name() string {
+	return "history"
+}
+
+func (c *HistoryCommand) Description() string {
+	return "Visar vad du har streckat"
+}
+
+func (c *HistoryCommand) Version() string {
+	return "1.0.0"
+}
+
+func (c *HistoryCommand) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
+func (c *HistoryCommand) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Name:        "user",
+			Description: "Visa en annan användares historik (endast admin)",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "from",
+			Description: "Första dagen i formatet ÅÅÅÅ-MM-DD",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "to",
+			Description: "Sista dagen i formatet ÅÅÅÅ-MM-DD",
+			Required:    false,
+		},
+	}
+}
+
+func (c *HistoryCommand) Run(ctx ken.Context) (err error) {
+	locale := discord.Locale(ctx)
+
+	discordUser := ctx.User()
+	if userArg, ok := ctx.Options().GetByNameOptional("user"); ok {
+		discordUser = userArg.UserValue(ctx)
+		if discordUser.ID != ctx.User().ID && !discord.IsAdmin(ctx) {
+			return ctx.RespondError(i18n.T(locale, "error.forbidden"), i18n.T(locale, "error.title"))
+		}
+	}
+
+	filter := models.TransactionFilter{UserID: discordUser.ID, Limit: historyPageSize}
+	if fromArg, ok := ctx.Options().GetByNameOptional("from"); ok {
+		if filter.From, err = time.ParseInLocation("2006-01-02", fromArg.StringValue(), time.Local); err != nil {
+			return ctx.RespondError(i18n.T(locale, "error.invalid_date"), i18n.T(locale, "error.title"))
+		}
+	}
+	if toArg, ok := ctx.Options().GetByNameOptional("to"); ok {
+		if filter.To, err = time.ParseInLocation("2006-01-02", toArg.StringValue(), time.Local); err != nil {
+			return ctx.RespondError(i18n.T(locale, "error.invalid_date"), i18n.T(locale, "error.title"))
+		}
+		filter.To = filter.To.AddDate(0, 0, 1)
+	}
+
+	return respondHistory(ctx, discordUser, filter)
+}
+
+// respondHistory responds with the first page of the transactions selected
+// by filter, with buttons to page through the rest.
+func respondHistory(ctx ken.Context, discordUser *discordgo.User, filter models.TransactionFilter) (err error) {
+	ctx.SetEphemeral(true)
+	if err = ctx.Defer(); err != nil {
+		return
+	}
+
+	db := discord.Database(ctx)
+	locale := discord.Locale(ctx)
+
+	embed, total, err := historyPage(db, locale, discordUser, filter)
+	if err != nil {
+		log.Printf("error getting history: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "history.failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	if total <= historyPageSize {
+		return ctx.FollowUpEmbed(embed).Send().Error
+	}
+
+	// The custom ids are unique to this interaction so that several open
+	// histories page independently.
+	prevID := "history-prev-" + ctx.GetEvent().ID
+	nextID := "history-next-" + ctx.GetEvent().ID
+
+	// show replaces the message with the page starting at offset.
+	show := func(cctx ken.ComponentContext, offset int) bool {
+		page := filter
+		page.Offset = offset
+		embed, total, err := historyPage(db, locale, discordUser, page)
+		if err != nil {
+			log.Printf("error getting history: %v", err)
+			return false
+		}
+
+		err = cctx.Respond(&discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Embeds:     []*discordgo.MessageEmbed{embed},
+				Components: historyButtons(locale, prevID, nextID, offset, total),
+			},
+		})
+		if err != nil {
+			log.Printf("error updating history: %v", err)
+			return false
+		}
+
+		filter.Offset = offset
+		return true
+	}
+
+	fum := ctx.FollowUpEmbed(embed).AddComponents(func(cb *ken.ComponentBuilder) {
+		cb.AddActionsRow(func(b ken.ComponentAssembler) {
+			buttons := historyButtons(locale, prevID, nextID, 0, total)[0].(discordgo.ActionsRow).Components
+			// Each button pages relative to the page currently shown.
+			b.Add(buttons[0], func(cctx ken.ComponentContext) bool {
+				return show(cctx, max(filter.Offset-historyPageSize, 0))
+			})
+			b.Add(buttons[1], func(cctx ken.ComponentContext) bool {
+				return show(cctx, filter.Offset+historyPageSize)
+			})
+		}).Condition(func(cctx ken.ComponentContext) bool {
+			return cctx.User().ID == ctx.User().ID
+		})
+	}).Send()
+
+	if fum.Error == nil {
+		components := ctx.GetKen().Components()
+		time.AfterFunc(historyTimeout, func() {
+			components.Unregister(prevID, nextID)
+		})
+	}
+
+	return fum.Error
+}
+
+// historyPage returns the embed showing the transactions selected by filter
+// and how many transactions there are in total.
+func historyPage(db database.Database, locale i18n.Locale, user *discordgo.User, filter models.TransactionFilter) (*discordgo.MessageEmbed, int, error) {
+	transactions, total, err := db.GetUserTransactions(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: i18n.T(locale, "history.title", user.Username),
+	}
+
+	if total == 0 {
+		embed.Description = i18n.T(locale, "history.empty")
+		return embed, total, nil
+	}
+
+	var lines []string
+	for _, transaction := range transactions {
+		line := fmt.Sprintf("`%s` %dst %s, %.02fkr",
+			transaction.TransactionDate.In(time.Local).Format("2006-01-02 15:04"),
+			transaction.Quantity,
+			transaction.ProductName,
+			transaction.PricePaid*float64(transaction.Quantity))
+		if transaction.PriceType == "external" {
+			line = i18n.T(locale, "history.external", line)
+		}
+		lines = append(lines, line)
+	}
+
+	embed.Description = strings.Join(lines, "\n")
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: i18n.T(locale, "history.footer",
+			filter.Offset/historyPageSize+1, (total+historyPageSize-1)/historyPageSize, total),
+	}
+
+	return embed, total, nil
+}
+
+func historyButtons(locale i18n.Locale, prevID string, nextID string, offset int, total int) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					CustomID: prevID,
+					Label:    i18n.T(locale, "history.previous"),
+					Style:    discordgo.SecondaryButton,
+					Disabled: offset == 0,
+				},
+				discordgo.Button{
+					CustomID: nextID,
+					Label:    i18n.T(locale, "history.next"),
+					Style:    discordgo.SecondaryButton,
+					Disabled: offset+historyPageSize >= total,
+				},
+			},
+		},
+	}
+}
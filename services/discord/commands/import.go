@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
+	"gostrecka/services/importer"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+// maxImportSize is the largest CSV file accepted by /import.
+const maxImportSize = 1 << 20
+
+type ImportCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.SlashCommand     = (*ImportCommand)(nil)
+	_ discord.AdminCommand = (*ImportCommand)(nil)
+)
+
+func (c *ImportCommand) Name() string {
+	return "import"
+}
+
+func (c *ImportCommand) Description() string {
+	return "Skapar produkter från en CSV-fil"
+}
+
+func (c *ImportCommand) Version() string {
+	return "1.0.0"
+}
+
+func (c *ImportCommand) IsAdminOnly() bool {
+	return true
+}
+
+func (c *ImportCommand) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
+func (c *ImportCommand) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionAttachment,
+			Name:        "file",
+			Description: "CSV med kolumnerna name, purchase_price, internal_price, external_price och stock",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "apply",
+			Description: "Skapa produkterna, annars görs bara en provkörning",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Name:        "user",
+			Description: "Användaren som krediteras för lagersaldot, förvalt är du själv",
+			Required:    false,
+		},
+	}
+}
+
+func (c *ImportCommand) Run(ctx ken.Context) (err error) {
+	ctx.SetEphemeral(true)
+	if err = ctx.Defer(); err != nil {
+		return
+	}
+
+	apply := false
+	if applyArg, ok := ctx.Options().GetByNameOptional("apply"); ok {
+		apply = applyArg.BoolValue()
+	}
+
+	stockUser := ctx.User().ID
+	if userArg, ok := ctx.Options().GetByNameOptional("user"); ok {
+		stockUser = userArg.UserValue(nil).ID
+	}
+
+	locale := discord.Locale(ctx)
+	data, err := discord.DownloadAttachment(ctx, "file", maxImportSize)
+	if errors.Is(err, discord.ErrAttachmentTooLarge) {
+		return ctx.FollowUpError(i18n.T(locale, "import.too_large", maxImportSize/1024), i18n.T(locale, "error.title")).Send().Error
+	} else if err != nil {
+		log.Printf("error downloading import file: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "import.download_failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	db := discord.Database(ctx)
+	report, err := importer.ImportProducts(db, discord.Origin(ctx), stockUser, bytes.NewReader(data), apply)
+	if err != nil {
+		log.Printf("error importing products: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "import.failed", err.Error()), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	title := i18n.T(locale, "import.dry_run_title")
+	if report.Applied {
+		title = i18n.T(locale, "import.done_title")
+	}
+
+	return ctx.FollowUpEmbed(&discordgo.MessageEmbed{
+		Title:       title,
+		Description: "```\n" + truncate(report.Format(locale), 4000) + "\n```",
+	}).Send().Error
+}
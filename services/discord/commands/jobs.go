@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"errors"
+	"gostrecka/internal/utils/static"
+	"gostrecka/models"
+	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
+	"gostrecka/services/scheduler"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+type JobsCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.SlashCommand        = (*JobsCommand)(nil)
+	_ ken.AutocompleteCommand = (*JobsCommand)(nil)
+	_ discord.AdminCommand    = (*JobsCommand)(nil)
+)
+
+func (c *JobsCommand) Name() string {
+	return "jobs"
+}
+
+func (c *JobsCommand) Description() string {
+	return "Visar och startar schemalagda jobb"
+}
+
+func (c *JobsCommand) Version() string {
+	return "1.0.0"
+}
+
+func (c *JobsCommand) IsAdminOnly() bool {
+	return true
+}
+
+func (c *JobsCommand) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
+func (c *JobsCommand) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "Visar alla jobb och när de körs",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "run",
+			Description: "Kör ett jobb direkt",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "job",
+					Description:  "Jobbet att köra",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		},
+	}
+}
+
+func (c *JobsCommand) Autocomplete(ctx *ken.AutocompleteContext) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	input, _ := ctx.SubCommand().GetInput("job")
+	input = strings.ToLower(input)
+
+	jobs, err := ctx.Get(static.DiScheduler).(*scheduler.SchedulerService).Jobs()
+	if err != nil {
+		return nil, err
+	}
+
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(jobs))
+	for _, job := range jobs {
+		if strings.Contains(job.Name, input) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  job.Name,
+				Value: job.Name,
+			})
+		}
+	}
+
+	return choices, nil
+}
+
+func (c *JobsCommand) Run(ctx ken.Context) (err error) {
+	ctx.SetEphemeral(true)
+
+	if !discord.DefaultDatabase(ctx) {
+		locale := discord.Locale(ctx)
+		return ctx.RespondError(i18n.T(locale, "error.default_guild"), i18n.T(locale, "error.title"))
+	}
+	return ctx.HandleSubCommands(
+		ken.SubCommandHandler{Name: "list", Run: c.list},
+		ken.SubCommandHandler{Name: "run", Run: c.run},
+	)
+}
+
+func (c *JobsCommand) list(ctx ken.SubCommandContext) (err error) {
+	locale := discord.Locale(ctx)
+	jobs, err := ctx.Get(static.DiScheduler).(*scheduler.SchedulerService).Jobs()
+	if err != nil {
+		log.Printf("error listing jobs: %v", err)
+		return ctx.RespondError(i18n.T(locale, "jobs.failed"), i18n.T(locale, "error.title"))
+	}
+
+	var fields []*discordgo.MessageEmbedField
+	for _, job := range jobs {
+		// Jobs are described in Swedish where they are registered, like
+		// commands, and translated by "job." and their name.
+		description := job.Description
+		if translated, ok := i18n.Lookup(locale, "job."+job.Name); ok {
+			description = translated
+		}
+
+		schedule := i18n.T(locale, "jobs.manual")
+		if job.Schedule != "" {
+			schedule = i18n.T(locale, "jobs.next", job.Schedule, job.Next.Unix())
+		}
+
+		lastRun := i18n.T(locale, "jobs.never")
+		switch {
+		case job.Running:
+			lastRun = i18n.T(locale, "jobs.running")
+		case job.LastRun.Status == models.JobOk:
+			lastRun = i18n.T(locale, "jobs.ok", job.LastRun.StartedAt.Unix())
+		case job.LastRun.Status == models.JobFailed:
+			lastRun = i18n.T(locale, "jobs.failed_run", job.LastRun.StartedAt.Unix(), job.LastRun.Error)
+		case job.LastRun.Status == models.JobRunning:
+			lastRun = i18n.T(locale, "jobs.aborted", job.LastRun.StartedAt.Unix())
+		}
+
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  job.Name,
+			Value: truncate(i18n.T(locale, "jobs.job", description, schedule, lastRun), 1024),
+		})
+	}
+
+	return ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title:  i18n.T(locale, "jobs.title"),
+		Fields: fields,
+	})
+}
+
+func (c *JobsCommand) run(ctx ken.SubCommandContext) (err error) {
+	name := ctx.Options().GetByName("job").StringValue()
+	locale := discord.Locale(ctx)
+
+	if err = ctx.Defer(); err != nil {
+		return
+	}
+
+	err = ctx.Get(static.DiScheduler).(*scheduler.SchedulerService).Trigger(name)
+	switch {
+	case errors.Is(err, scheduler.ErrUnknownJob):
+		return ctx.FollowUpError(i18n.T(locale, "jobs.unknown", name), i18n.T(locale, "error.title")).Send().Error
+	case errors.Is(err, scheduler.ErrJobRunning):
+		return ctx.FollowUpError(i18n.T(locale, "jobs.already_running", name), i18n.T(locale, "error.title")).Send().Error
+	case err != nil:
+		return ctx.FollowUpError(i18n.T(locale, "jobs.run_failed", name, err.Error()), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	return ctx.FollowUpEmbed(&discordgo.MessageEmbed{
+		Description: i18n.T(locale, "jobs.done", name),
+	}).Send().Error
+}
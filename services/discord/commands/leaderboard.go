@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"gostrecka/internal/utils/static"
+	"gostrecka/models"
+	"gostrecka/services/discord"
+	"gostrecka/services/env"
+	"gostrecka/services/i18n"
+	"gostrecka/services/leaderboard"
+	"gostrecka/utils"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+type LeaderboardCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.SlashCommand        = (*LeaderboardCommand)(nil)
+	_ ken.AutocompleteCommand = (*LeaderboardCommand)(nil)
+)
+
+func (c *LeaderboardCommand) Name() string {
+	return "leaderboard"
+}
+
+func (c *LeaderboardCommand) Description() string {
+	return "Visar topplistan och hur den har vuxit fram"
+}
+
+func (c *LeaderboardCommand) Version() string {
+	return "1.0.0"
+}
+
+func (c *LeaderboardCommand) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
+func (c *LeaderboardCommand) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "period",
+			Description: "Vilken period topplistan gäller, förvalt är samma som i kiosken",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Ikväll", Value: models.LeaderboardTonight},
+				{Name: "Veckan", Value: models.LeaderboardWeek},
+				{Name: "Terminen", Value: models.LeaderboardTerm},
+				{Name: "Genom tiderna", Value: models.LeaderboardAllTime},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "metric",
+			Description: "Vad topplistan räknar, förvalt är samma som i kiosken",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Antal", Value: models.LeaderboardUnits},
+				{Name: "Belopp", Value: models.LeaderboardSpend},
+			},
+		},
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "product",
+			Description:  "Räkna bara en produkt",
+			Required:     false,
+			Autocomplete: true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "category",
+			Description: "Räkna bara en produktkategori",
+			Required:    false,
+		},
+	}
+}
+
+func (c *LeaderboardCommand) Autocomplete(ctx *ken.AutocompleteContext) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	return discord.AutocompleteOption(ctx)
+}
+
+func (c *LeaderboardCommand) Run(ctx ken.Context) (err error) {
+	if err = ctx.Defer(); err != nil {
+		return
+	}
+
+	db := discord.Database(ctx)
+	locale := discord.Locale(ctx)
+	config := ctx.Get(static.DiConfig).(env.Config).Leaderboard
+	query := leaderboard.Default(config)
+
+	// A product or category given here replaces the kiosk's rather than
+	// narrowing it.
+	if periodArg, ok := ctx.Options().GetByNameOptional("period"); ok {
+		query.Window = periodArg.StringValue()
+	}
+	if metricArg, ok := ctx.Options().GetByNameOptional("metric"); ok {
+		query.Metric = metricArg.StringValue()
+	}
+	if productArg, ok := ctx.Options().GetByNameOptional("product"); ok {
+		if query.ProductID, err = strconv.ParseInt(productArg.StringValue(), 10, 64); err != nil {
+			return ctx.FollowUpError(i18n.T(locale, "error.product_not_found"), i18n.T(locale, "error.title")).Send().Error
+		}
+		query.Category = ""
+	}
+	if categoryArg, ok := ctx.Options().GetByNameOptional("category"); ok {
+		query.Category = categoryArg.StringValue()
+		query.ProductID = 0
+	}
+
+	now := time.Now()
+	filter, err := leaderboard.Filter(config, query, now)
+	if errors.Is(err, leaderboard.ErrUnknownWindow) || errors.Is(err, leaderboard.ErrUnknownMetric) {
+		return ctx.FollowUpError(i18n.T(locale, "leaderboard.unknown"), i18n.T(locale, "error.title")).Send().Error
+	}
+	if err != nil {
+		log.Printf("error resolving leaderboard: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "leaderboard.failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	title := i18n.T(locale, "leaderboard.title_"+query.Window)
+	if query.ProductID != 0 {
+		product, _, err := db.GetProductIdent(query.ProductID)
+		if err != nil {
+			return ctx.FollowUpError(i18n.T(locale, "error.product_not_found"), i18n.T(locale, "error.title")).Send().Error
+		}
+		title += ", " + product.Name
+	} else if query.Category != "" {
+		title += ", " + query.Category
+	}
+
+	standings, err := db.GetTransactionLeaderboard(filter)
+	if err != nil {
+		log.Printf("error getting leaderboard: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "leaderboard.failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	transactions, err := db.GetLatestTransactions(filter)
+	if err != nil {
+		log.Printf("error getting latest transactions: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "leaderboard.failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	var buf bytes.Buffer
+	err = utils.GenerateLeaderboardPNG(utils.LeaderboardChart{
+		Title:        title,
+		Metric:       query.Metric,
+		From:         filter.From,
+		To:           now,
+		Standings:    standings,
+		Transactions: transactions,
+		Locale:       locale,
+	}, &buf)
+	if err != nil {
+		log.Printf("error rendering leaderboard: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "leaderboard.render_failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	return ctx.FollowUp(true, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title: title,
+				Image: &discordgo.MessageEmbedImage{URL: "attachment://leaderboard.png"},
+			},
+		},
+		Files: []*discordgo.File{
+			{
+				Name:        "leaderboard.png",
+				ContentType: "image/png",
+				Reader:      &buf,
+			},
+		},
+	}).Send().Error
+}
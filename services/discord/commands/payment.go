@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"gostrecka/models"
+	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+// PaymentCommand lets admins register that a user has paid off debt, for
+// example by Swish, or write off debt that will not be paid.
+type PaymentCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.SlashCommand     = (*PaymentCommand)(nil)
+	_ discord.AdminCommand = (*PaymentCommand)(nil)
+)
+
+func (c *PaymentCommand) Name() string {
+	return "payment"
+}
+
+func (c *PaymentCommand) Description() string {
+	return "Registrerar en inbetalning eller avskrivning av en användares skuld"
+}
+
+func (c *PaymentCommand) Version() string {
+	return "1.0.0"
+}
+
+func (c *PaymentCommand) IsAdminOnly() bool {
+	return true
+}
+
+func (c *PaymentCommand) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
+func (c *PaymentCommand) Options() []*discordgo.ApplicationCommandOption {
+	var amountMinValue float64 = 0.01
+
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Name:        "user",
+			Description: "Användaren som har betalat",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionNumber,
+			Name:        "amount",
+			Description: "Beloppet i kronor",
+			Required:    true,
+			MinValue:    &amountMinValue,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "type",
+			Description: "Typ av betalning, förvalt är inbetalning",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Inbetalning", Value: models.LedgerPayment},
+				{Name: "Avskrivning", Value: models.LedgerWriteOff},
+			},
+		},
+	}
+}
+
+func (c *PaymentCommand) Run(ctx ken.Context) (err error) {
+	user := ctx.Options().GetByName("user").UserValue(ctx)
+	amount := ctx.Options().GetByName("amount").FloatValue()
+
+	paymentType := models.LedgerPayment
+	if typeArg, ok := ctx.Options().GetByNameOptional("type"); ok {
+		paymentType = typeArg.StringValue()
+	}
+
+	db := discord.Database(ctx)
+	locale := discord.Locale(ctx)
+
+	_, _, err = db.GetUser(user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ctx.RespondError(i18n.T(locale, "error.user_not_found"), i18n.T(locale, "error.title"))
+	}
+	if err != nil {
+		log.Printf("error getting user: %v", err)
+		return ctx.RespondError(i18n.T(locale, "error.internal"), i18n.T(locale, "error.title"))
+	}
+
+	paymentId, err := db.RecordPayment(discord.Origin(ctx), user.ID, amount, paymentType)
+	if err != nil {
+		log.Printf("error recording payment: %v", err)
+		return ctx.RespondError(i18n.T(locale, "payment.failed"), i18n.T(locale, "error.title"))
+	}
+
+	// The payment is recorded either way, the balance is only shown.
+	_, balance, err := db.GetUser(user.ID)
+	if err != nil {
+		log.Printf("error getting balance: %v", err)
+	}
+
+	return ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title:       i18n.T(locale, "payment.title_"+paymentType),
+		Description: i18n.T(locale, "payment.done_"+paymentType, amount, fmt.Sprintf("<@%s>", user.ID), balance.DebtIncurred),
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("#%d", paymentId)},
+	})
+}
@@ -2,7 +2,7 @@ package commands
 
 import (
 	"fmt"
-	"gostrecka/services/database"
+	"gostrecka/services/discord"
 	"gostrecka/utils"
 	"os"
 
@@ -10,11 +10,13 @@ import (
 	"github.com/zekrotja/ken"
 )
 
-type PrintCommand struct{}
+type PrintCommand struct {
+	discord.GuildScope
+}
 
 // Description implements ken.SlashCommand.
 func (p *PrintCommand) Description() string {
-	return "Returns pdfs' of scannable barcodes"
+	return "Skapar PDF:er med streckkoder att skanna"
 }
 
 // Name implements ken.SlashCommand.
@@ -31,7 +33,7 @@ func (p *PrintCommand) Options() []*discordgo.ApplicationCommandOption {
 func (p *PrintCommand) Run(ctx ken.Context) (err error) {
 	messageId := ctx.GetEvent().ID
 
-	db := ctx.Get("database").(database.Database)
+	db := discord.Database(ctx)
 
 	upcRows, _ := db.GetUserUpcs()
 	productRows, _ := db.GetProductUpcs()
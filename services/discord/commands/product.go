@@ -2,17 +2,20 @@ package commands
 
 import (
 	"fmt"
-	"gostrecka/internal/utils/static"
-	"gostrecka/services/database"
+	"gostrecka/models"
 	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
 	"log"
 	"strconv"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/zekrotja/ken"
 )
 
-type ProductCommand struct{}
+type ProductCommand struct {
+	discord.GuildScope
+}
 
 var (
 	_ ken.SlashCommand        = (*ProductCommand)(nil)
@@ -43,30 +46,18 @@ func (c *ProductCommand) Options() []*discordgo.ApplicationCommandOption {
 			Type:        discordgo.ApplicationCommandOptionSubCommand,
 			Name:        "create",
 			Description: "Skapar en produkt",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "edit",
+			Description: "Ändrar namn, kategori, priser eller streckkod för en produkt",
 			Options: []*discordgo.ApplicationCommandOption{
 				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "name",
-					Description: "Name of product",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionNumber,
-					Name:        "purchase_price",
-					Description: "Vad betalade du för den när den köptes in?",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionNumber,
-					Name:        "internal_price",
-					Description: "Internpris?",
-					Required:    true,
-				},
-				{
-					Type:        discordgo.ApplicationCommandOptionNumber,
-					Name:        "external_price",
-					Description: "Externpris?",
-					Required:    true,
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "product",
+					Description:  "Produkt att ändra",
+					Required:     true,
+					Autocomplete: true,
 				},
 			},
 		},
@@ -130,6 +121,26 @@ func (c *ProductCommand) Options() []*discordgo.ApplicationCommandOption {
 				},
 			},
 		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "category",
+			Description: "Sätt kategorin en produkt redovisas under",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "product",
+					Description:  "Produkt att sätta kategori på",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "category",
+					Description: "Kategorin, utelämna för att ta bort kategorin",
+					Required:    false,
+				},
+			},
+		},
 	}
 }
 
@@ -144,8 +155,10 @@ func (c *ProductCommand) Autocomplete(ctx *ken.AutocompleteContext) ([]*discordg
 func (c *ProductCommand) Run(ctx ken.Context) (err error) {
 	err = ctx.HandleSubCommands(
 		ken.SubCommandHandler{Name: "create", Run: c.create},
+		ken.SubCommandHandler{Name: "edit", Run: c.edit},
 		ken.SubCommandHandler{Name: "stock", Run: c.stock},
 		ken.SubCommandHandler{Name: "info", Run: c.info},
+		ken.SubCommandHandler{Name: "category", Run: c.category},
 	)
 
 	return
@@ -155,43 +168,49 @@ func (c *ProductCommand) info(ctx ken.SubCommandContext) (err error) {
 	productArg := ctx.Options().GetByName("product")
 
 	log.Printf("productArg: %v", productArg.StringValue())
+	locale := discord.Locale(ctx)
 
 	ProductID, err := strconv.ParseInt(productArg.StringValue(), 10, 64)
 	if err != nil {
 		log.Printf("error converting product Id to int64: %v", err)
-		return ctx.RespondError("Intern fel", "Fel")
+		return ctx.RespondError(i18n.T(locale, "error.internal"), i18n.T(locale, "error.title"))
 	}
 
-	db := ctx.Get(static.DiDatabase).(database.Database)
+	db := discord.Database(ctx)
 	product, price, err := db.GetProductIdent(ProductID)
 	if err != nil {
 		fmt.Printf("error getting product: %v", err)
-		return ctx.RespondError("Produkten hittades inte", "Fel")
+		return ctx.RespondError(i18n.T(locale, "error.product_not_found"), i18n.T(locale, "error.title"))
 	}
 
 	err = ctx.RespondEmbed(&discordgo.MessageEmbed{
-		Title:       "Produkt",
+		Title:       i18n.T(locale, "product.title"),
 		Description: product.Name,
 		Fields: []*discordgo.MessageEmbedField{
 			{
-				Name:   "Inköpspris",
+				Name:   i18n.T(locale, "product.purchase_price"),
 				Value:  fmt.Sprintf("%.2f", price.PurchasePrice),
 				Inline: true,
 			},
 			{
-				Name:   "Internpris",
+				Name:   i18n.T(locale, "product.internal_price"),
 				Value:  fmt.Sprintf("%.2f", price.InternalPrice),
 				Inline: true,
 			},
 			{
-				Name:   "Externpris",
+				Name:   i18n.T(locale, "product.external_price"),
 				Value:  fmt.Sprintf("%.2f", price.ExternalPrice),
 				Inline: true,
 			},
 
 			{
-				Name:   "Lagersaldo",
-				Value:  fmt.Sprintf("%dst", product.TotalStock),
+				Name:   i18n.T(locale, "product.stock"),
+				Value:  i18n.T(locale, "product.stock_count", product.TotalStock),
+				Inline: true,
+			},
+			{
+				Name:   i18n.T(locale, "product.category"),
+				Value:  categoryName(locale, product.Category),
 				Inline: true,
 			},
 		},
@@ -200,49 +219,68 @@ func (c *ProductCommand) info(ctx ken.SubCommandContext) (err error) {
 	return
 }
 
-func (c *ProductCommand) create(ctx ken.SubCommandContext) (err error) {
-	if err = ctx.Defer(); err != nil {
-		return
-	}
-	name := ctx.Options().GetByName("name").StringValue()
-	purchasePrice := ctx.Options().GetByName("purchase_price").FloatValue()
-	internalPrice := ctx.Options().GetByName("internal_price").FloatValue()
-	externalPrice := ctx.Options().GetByName("external_price").FloatValue()
+func (c *ProductCommand) category(ctx ken.SubCommandContext) (err error) {
+	productArg := ctx.Options().GetByName("product")
 
-	db := ctx.Get(static.DiDatabase).(database.Database)
+	category := ""
+	if categoryArg, ok := ctx.Options().GetByNameOptional("category"); ok {
+		category = strings.TrimSpace(categoryArg.StringValue())
+	}
+	locale := discord.Locale(ctx)
 
-	err = db.CreateProduct(name, purchasePrice, internalPrice, externalPrice)
+	ProductID, err := strconv.ParseInt(productArg.StringValue(), 10, 64)
+	if err != nil {
+		log.Printf("error converting product Id to int64: %v", err)
+		return ctx.RespondError(i18n.T(locale, "error.internal"), i18n.T(locale, "error.title"))
+	}
 
+	db := discord.Database(ctx)
+	product, _, err := db.GetProductIdent(ProductID)
 	if err != nil {
-		return nil
+		return ctx.RespondError(i18n.T(locale, "error.product_not_found"), i18n.T(locale, "error.title"))
 	}
 
-	ctx.RespondEmbed(&discordgo.MessageEmbed{
-		Title:       "Produkt",
-		Description: "Produkt skapad: " + name,
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Dags att lägga till lagersaldo!",
-		},
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "Inköpspris",
-				Value:  fmt.Sprintf("%.2f", purchasePrice),
-				Inline: true,
-			},
-			{
-				Name:   "Internpris",
-				Value:  fmt.Sprintf("%.2f", internalPrice),
-				Inline: true,
-			},
-			{
-				Name:   "Externpris",
-				Value:  fmt.Sprintf("%.2f", externalPrice),
-				Inline: true,
-			},
-		},
+	if err = db.SetProductCategory(discord.Origin(ctx), ProductID, category); err != nil {
+		log.Printf("error setting product category: %v", err)
+		return ctx.RespondError(i18n.T(locale, "product.category_failed"), i18n.T(locale, "error.title"))
+	}
+
+	return ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title:       i18n.T(locale, "product.category_title"),
+		Description: i18n.T(locale, "product.category_set", product.Name, categoryName(locale, category)),
 	})
+}
 
-	return
+// categoryName returns the category as shown to users.
+func categoryName(locale i18n.Locale, category string) string {
+	if category == "" {
+		return i18n.T(locale, "product.uncategorised")
+	}
+
+	return category
+}
+
+func (c *ProductCommand) create(ctx ken.SubCommandContext) (err error) {
+	return newProductForm(ctx, models.Product{}, models.ProductPrice{}).start(ctx)
+}
+
+func (c *ProductCommand) edit(ctx ken.SubCommandContext) (err error) {
+	productArg := ctx.Options().GetByName("product")
+	locale := discord.Locale(ctx)
+
+	ProductID, err := strconv.ParseInt(productArg.StringValue(), 10, 64)
+	if err != nil {
+		log.Printf("error converting product Id to int64: %v", err)
+		return ctx.RespondError(i18n.T(locale, "error.internal"), i18n.T(locale, "error.title"))
+	}
+
+	db := discord.Database(ctx)
+	product, price, err := db.GetProductIdent(ProductID)
+	if err != nil {
+		return ctx.RespondError(i18n.T(locale, "error.product_not_found"), i18n.T(locale, "error.title"))
+	}
+
+	return newProductForm(ctx, product, price).start(ctx)
 }
 
 func (c *ProductCommand) stock(ctx ken.SubCommandContext) (err error) {
@@ -254,7 +292,8 @@ func (c *ProductCommand) stock(ctx ken.SubCommandContext) (err error) {
 	internalPrice, ipExists := ctx.Options().GetByNameOptional("internal_price")
 	externalPrice, epExists := ctx.Options().GetByNameOptional("external_price")
 
-	db := ctx.Get(static.DiDatabase).(database.Database)
+	db := discord.Database(ctx)
+	locale := discord.Locale(ctx)
 
 	var discordUser *discordgo.User
 	if userSupplied {
@@ -265,25 +304,25 @@ func (c *ProductCommand) stock(ctx ken.SubCommandContext) (err error) {
 
 	user, oldWallet, err := db.GetUser(discordUser.ID)
 	if err != nil {
-		return ctx.RespondError("Användaren är inte registrerad i systemet, registrera med /user create <person>", "Fel")
+		return ctx.RespondError(i18n.T(locale, "error.not_registered", discordUser.Mention()), i18n.T(locale, "error.title"))
 	}
 
 	ProductID, err := strconv.ParseInt(productArg.StringValue(), 10, 64)
 	if err != nil {
 		log.Printf("error converting product Id to int64: %v", err)
-		return ctx.RespondError("Intern fel", "Fel")
+		return ctx.RespondError(i18n.T(locale, "error.internal"), i18n.T(locale, "error.title"))
 	}
 
 	product, price, err := db.GetProductIdent(ProductID)
 	if err != nil {
 		fmt.Printf("error getting product: %v", err)
-		return ctx.RespondError("Produkten hittades inte", "Fel")
+		return ctx.RespondError(i18n.T(locale, "error.product_not_found"), i18n.T(locale, "error.title"))
 	}
 
-	err = db.AddStock(product.ID, user.ID, amount.IntValue())
+	err = db.AddStock(discord.Origin(ctx), product.ID, user.ID, amount.IntValue())
 	if err != nil {
 		fmt.Printf("error getting product: %v", err)
-		return ctx.RespondError("Kunde inte lägga till lagersaldo", "Fel")
+		return ctx.RespondError(i18n.T(locale, "product.stock_failed"), i18n.T(locale, "error.title"))
 	}
 
 	if ppExists || ipExists || epExists {
@@ -297,38 +336,38 @@ func (c *ProductCommand) stock(ctx ken.SubCommandContext) (err error) {
 			price.ExternalPrice = externalPrice.FloatValue()
 		}
 
-		err = db.UpdatePrice(product.ID, price.PurchasePrice, price.InternalPrice, price.ExternalPrice)
+		err = db.UpdatePrice(discord.Origin(ctx), product.ID, price.PurchasePrice, price.InternalPrice, price.ExternalPrice)
 		if err != nil {
-			return ctx.RespondError("Kunde inte uppdatera pris", "Fel")
+			return ctx.RespondError(i18n.T(locale, "product.price_failed"), i18n.T(locale, "error.title"))
 		}
 	}
 
 	user, wallet, err := db.GetUser(discordUser.ID)
 	if err != nil {
-		return ctx.RespondError("Kunde inte hämta användare", "Fel")
+		return ctx.RespondError(i18n.T(locale, "product.user_failed"), i18n.T(locale, "error.title"))
 	}
 
 	ctx.RespondEmbed(&discordgo.MessageEmbed{
-		Title:       "Lagersaldo",
-		Description: fmt.Sprintf("Lagersaldo uppdaterat för %s", product.Name),
+		Title:       i18n.T(locale, "product.stock"),
+		Description: i18n.T(locale, "product.stock_updated", product.Name),
 
 		Fields: []*discordgo.MessageEmbedField{
 			{
-				Name:  "Inköpare",
-				Value: fmt.Sprintf("%s fick %.02f kronor att handla för 😋", discordUser.Mention(), wallet.TotalCreditsEarned-oldWallet.TotalCreditsEarned),
+				Name:  i18n.T(locale, "product.buyer"),
+				Value: i18n.T(locale, "product.buyer_credit", discordUser.Mention(), wallet.TotalCreditsEarned-oldWallet.TotalCreditsEarned),
 			},
 			{
-				Name:   "Inköpspris",
+				Name:   i18n.T(locale, "product.purchase_price"),
 				Value:  fmt.Sprintf("%.2f", price.PurchasePrice),
 				Inline: true,
 			},
 			{
-				Name:   "Internpris",
+				Name:   i18n.T(locale, "product.internal_price"),
 				Value:  fmt.Sprintf("%.2f", price.InternalPrice),
 				Inline: true,
 			},
 			{
-				Name:   "Externpris",
+				Name:   i18n.T(locale, "product.external_price"),
 				Value:  fmt.Sprintf("%.2f", price.ExternalPrice),
 				Inline: true,
 			},
@@ -0,0 +1,426 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"gostrecka/internal/utils/static"
+	"gostrecka/models"
+	"gostrecka/services/database"
+	"gostrecka/services/discord"
+	"gostrecka/services/env"
+	"gostrecka/services/i18n"
+	"gostrecka/services/products"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+// productFormTimeout is how long a product form can be filled in before
+// its buttons stop working.
+const productFormTimeout = 15 * time.Minute
+
+// productForm creates or edits a product through a modal. The form lives in
+// an ephemeral message that shows either what is wrong with the values or
+// what will be saved, and is only saved once confirmed.
+type productForm struct {
+	mu sync.Mutex
+
+	db         database.Database
+	config     env.Config
+	components *ken.ComponentHandler
+	// locale is the owner's language, which the form is written in.
+	locale i18n.Locale
+
+	ownerID string
+	id      string
+	// product and price are the product being edited, product.ID is zero
+	// when a product is created.
+	product models.Product
+	price   models.ProductPrice
+
+	// The values as typed, so the modal opens with them again.
+	name     string
+	category string
+	prices   string
+	stock    string
+	upc      string
+
+	form products.Form
+	done bool
+}
+
+func newProductForm(ctx ken.Context, product models.Product, price models.ProductPrice) *productForm {
+	f := &productForm{
+		db:         discord.Database(ctx),
+		config:     ctx.Get(static.DiConfig).(env.Config),
+		components: ctx.GetKen().Components(),
+		locale:     discord.Locale(ctx),
+		ownerID:    ctx.User().ID,
+		id:         ctx.GetEvent().ID,
+		product:    product,
+		price:      price,
+	}
+
+	if product.ID != 0 {
+		f.name = product.Name
+		f.category = product.Category
+		f.prices = products.FormatPrices(price.PurchasePrice, price.InternalPrice, price.ExternalPrice)
+		f.upc = product.Upc
+	}
+
+	return f
+}
+
+func (f *productForm) buttonID(button string) string {
+	return fmt.Sprintf("product-form-%s-%s", button, f.id)
+}
+
+func (f *productForm) title() string {
+	if f.product.ID == 0 {
+		return i18n.T(f.locale, "form.new")
+	}
+
+	return i18n.T(f.locale, "form.edit", f.product.Name)
+}
+
+// start responds with the form's message and starts handling its buttons.
+func (f *productForm) start(ctx ken.Context) error {
+	ctx.SetEphemeral(true)
+	err := ctx.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Title:       f.title(),
+					Description: i18n.T(f.locale, "form.intro"),
+				},
+			},
+			Components: f.buttons("open", "cancel"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	f.components.Register(f.buttonID("open"), f.open)
+	f.components.Register(f.buttonID("save"), f.save)
+	f.components.Register(f.buttonID("cancel"), f.cancel)
+	time.AfterFunc(productFormTimeout, f.close)
+
+	return nil
+}
+
+func (f *productForm) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.done = true
+	f.components.Unregister(f.buttonID("open"), f.buttonID("save"), f.buttonID("cancel"))
+}
+
+func (f *productForm) buttons(names ...string) []discordgo.MessageComponent {
+	labels := map[string]discordgo.Button{
+		"open":   {Label: i18n.T(f.locale, "form.open"), Style: discordgo.PrimaryButton},
+		"save":   {Label: i18n.T(f.locale, "form.save"), Style: discordgo.SuccessButton},
+		"cancel": {Label: i18n.T(f.locale, "form.cancel"), Style: discordgo.SecondaryButton},
+	}
+
+	row := discordgo.ActionsRow{}
+	for _, name := range names {
+		button := labels[name]
+		button.CustomID = f.buttonID(name)
+		if name == "open" && f.form.Name != "" {
+			button.Label = i18n.T(f.locale, "form.change")
+		}
+		row.Components = append(row.Components, button)
+	}
+
+	return []discordgo.MessageComponent{row}
+}
+
+// allowed tells anyone but the user who opened the form that it is not
+// theirs, in their language.
+func (f *productForm) allowed(ctx ken.ComponentContext) bool {
+	if ctx.User().ID == f.ownerID {
+		return true
+	}
+
+	ctx.SetEphemeral(true)
+	ctx.RespondMessage(i18n.T(discord.InteractionLocale(f.db, f.config, ctx.GetEvent()), "form.not_yours"))
+	return false
+}
+
+func (f *productForm) open(ctx ken.ComponentContext) bool {
+	if !f.allowed(ctx) {
+		return false
+	}
+
+	f.mu.Lock()
+	inputs := []discordgo.TextInput{
+		{CustomID: "name", Label: i18n.T(f.locale, "form.name"), Style: discordgo.TextInputShort, Required: true, MaxLength: products.MaxNameLength, Value: f.name},
+		{CustomID: "category", Label: i18n.T(f.locale, "product.category"), Style: discordgo.TextInputShort, Required: false, MaxLength: 50, Value: f.category},
+		{CustomID: "prices", Label: i18n.T(f.locale, "form.prices"), Style: discordgo.TextInputShort, Required: true, Placeholder: "10 / 12,5 / 15", Value: f.prices},
+	}
+	if f.product.ID == 0 {
+		inputs = append(inputs, discordgo.TextInput{CustomID: "stock", Label: i18n.T(f.locale, "form.stock"), Style: discordgo.TextInputShort, Required: false, Placeholder: "0", Value: f.stock})
+	}
+	inputs = append(inputs, discordgo.TextInput{CustomID: "upc", Label: i18n.T(f.locale, "form.upc"), Style: discordgo.TextInputShort, Required: false, Placeholder: i18n.T(f.locale, "form.upc_placeholder"), Value: f.upc})
+	f.mu.Unlock()
+
+	submitted, err := ctx.OpenModal(f.title(), "", func(b ken.ComponentAssembler) {
+		for _, input := range inputs {
+			b.AddActionsRow(func(b ken.ComponentAssembler) {
+				b.Add(input, nil)
+			})
+		}
+	})
+	if err != nil {
+		log.Printf("error opening product form: %v", err)
+		return false
+	}
+
+	select {
+	case mctx := <-submitted:
+		return f.submit(mctx)
+	case <-time.After(productFormTimeout):
+		return false
+	}
+}
+
+func (f *productForm) submit(ctx ken.ModalContext) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.done {
+		ctx.Respond(&discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+		return false
+	}
+
+	f.name = strings.TrimSpace(ctx.GetComponentByID("name").GetValue())
+	f.category = strings.TrimSpace(ctx.GetComponentByID("category").GetValue())
+	f.prices = strings.TrimSpace(ctx.GetComponentByID("prices").GetValue())
+	f.stock = strings.TrimSpace(ctx.GetComponentByID("stock").GetValue())
+	f.upc = strings.TrimSpace(ctx.GetComponentByID("upc").GetValue())
+
+	problems := f.parse()
+
+	embed := &discordgo.MessageEmbed{Title: f.title()}
+	var components []discordgo.MessageComponent
+	if len(problems) > 0 {
+		embed.Description = i18n.T(f.locale, "form.problems", strings.Join(problems, "\n- "))
+		components = f.buttons("open", "cancel")
+	} else {
+		embed.Description = i18n.T(f.locale, "form.confirm")
+		embed.Fields = f.fields()
+		components = f.buttons("save", "open", "cancel")
+	}
+
+	err := ctx.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+	if err != nil {
+		log.Printf("error responding to product form: %v", err)
+		return false
+	}
+
+	return true
+}
+
+// parse reads the typed values into the form and returns what is wrong
+// with them.
+func (f *productForm) parse() (problems []string) {
+	f.form = products.Form{Name: f.name, Category: f.category, Upc: f.upc}
+
+	var err error
+	f.form.PurchasePrice, f.form.InternalPrice, f.form.ExternalPrice, err = products.ParsePrices(f.prices)
+	if err != nil {
+		problems = append(problems, i18n.T(f.locale, "form.invalid_prices"))
+	}
+
+	if f.stock != "" {
+		if f.form.Stock, err = strconv.ParseInt(f.stock, 10, 64); err != nil {
+			problems = append(problems, i18n.T(f.locale, "form.invalid_stock"))
+		}
+	}
+
+	problems = append(problems, f.form.Validate(f.db, f.product.ID, f.locale)...)
+
+	if f.form.Stock > 0 {
+		if _, _, err := f.db.GetUser(f.ownerID); err != nil {
+			problems = append(problems, i18n.T(f.locale, "form.stock_unregistered"))
+		}
+	}
+
+	return
+}
+
+// fields shows what will be saved, with the current values of a product
+// being edited where they change.
+func (f *productForm) fields() []*discordgo.MessageEmbedField {
+	value := func(before string, after string) string {
+		if f.product.ID == 0 || before == after {
+			return after
+		}
+		return fmt.Sprintf("%s → %s", before, after)
+	}
+	price := func(p float64) string {
+		return fmt.Sprintf("%.2f", p)
+	}
+
+	upc := f.form.Upc
+	if upc == "" {
+		upc = f.product.Upc
+	}
+	if upc == "" {
+		upc = i18n.T(f.locale, "form.upc_auto")
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: i18n.T(f.locale, "form.name"), Value: value(f.product.Name, f.form.Name)},
+		{Name: i18n.T(f.locale, "product.category"), Value: value(categoryName(f.locale, f.product.Category), categoryName(f.locale, f.form.Category)), Inline: true},
+		{Name: i18n.T(f.locale, "form.upc"), Value: value(f.product.Upc, upc), Inline: true},
+		{Name: i18n.T(f.locale, "product.purchase_price"), Value: value(price(f.price.PurchasePrice), price(f.form.PurchasePrice)), Inline: true},
+		{Name: i18n.T(f.locale, "product.internal_price"), Value: value(price(f.price.InternalPrice), price(f.form.InternalPrice)), Inline: true},
+		{Name: i18n.T(f.locale, "product.external_price"), Value: value(price(f.price.ExternalPrice), price(f.form.ExternalPrice)), Inline: true},
+	}
+	if f.product.ID == 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: i18n.T(f.locale, "product.stock"), Value: i18n.T(f.locale, "product.stock_count", f.form.Stock), Inline: true})
+	}
+
+	return fields
+}
+
+func (f *productForm) save(ctx ken.ComponentContext) bool {
+	if !f.allowed(ctx) {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// A second click on save finds the form already saved.
+	if f.done {
+		ctx.Respond(&discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+		return false
+	}
+
+	// Someone else may have taken the name or barcode since the form was
+	// submitted.
+	embed := &discordgo.MessageEmbed{Title: f.title()}
+	if problems := f.parse(); len(problems) > 0 {
+		embed.Description = i18n.T(f.locale, "form.problems", strings.Join(problems, "\n- "))
+		return f.update(ctx, embed, f.buttons("open", "cancel"))
+	}
+
+	// The fields are taken before saving, which changes what the form
+	// compares against.
+	fields := f.fields()
+
+	var err error
+	if f.product.ID == 0 {
+		err = f.create(discord.Origin(ctx))
+		embed.Description = i18n.T(f.locale, "form.created", f.form.Name)
+		if f.form.Stock == 0 {
+			embed.Footer = &discordgo.MessageEmbedFooter{Text: i18n.T(f.locale, "form.add_stock")}
+		}
+	} else {
+		err = f.edit(discord.Origin(ctx))
+		embed.Description = i18n.T(f.locale, "form.updated", f.form.Name)
+	}
+
+	if err != nil {
+		log.Printf("error saving product: %v", err)
+		embed.Description = i18n.T(f.locale, "form.save_failed")
+		return f.update(ctx, embed, f.buttons("save", "open", "cancel"))
+	}
+
+	embed.Fields = fields
+	f.done = true
+	f.components.Unregister(f.buttonID("open"), f.buttonID("save"), f.buttonID("cancel"))
+
+	return f.update(ctx, embed, []discordgo.MessageComponent{})
+}
+
+func (f *productForm) create(origin models.Origin) error {
+	id, err := f.db.CreateProduct(origin, strings.TrimSpace(f.form.Name), f.form.PurchasePrice, f.form.InternalPrice, f.form.ExternalPrice)
+	if err != nil {
+		return err
+	}
+
+	// The product exists from here on, a failure below must not make a
+	// retry create it twice.
+	f.product.ID = id
+	f.product.Name = strings.TrimSpace(f.form.Name)
+	f.price = models.ProductPrice{PurchasePrice: f.form.PurchasePrice, InternalPrice: f.form.InternalPrice, ExternalPrice: f.form.ExternalPrice}
+
+	var errs []error
+	if f.form.Category != "" {
+		errs = append(errs, f.db.SetProductCategory(origin, id, f.form.Category))
+	}
+	if f.form.Upc != "" {
+		errs = append(errs, f.db.SetProductUpc(origin, id, f.form.Upc))
+	}
+	if f.form.Stock > 0 {
+		errs = append(errs, f.db.AddStock(origin, id, f.ownerID, f.form.Stock))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (f *productForm) edit(origin models.Origin) error {
+	var errs []error
+
+	if name := strings.TrimSpace(f.form.Name); name != f.product.Name {
+		errs = append(errs, f.db.RenameProduct(origin, f.product.ID, name))
+	}
+	if f.form.Category != f.product.Category {
+		errs = append(errs, f.db.SetProductCategory(origin, f.product.ID, f.form.Category))
+	}
+	if f.form.PurchasePrice != f.price.PurchasePrice || f.form.InternalPrice != f.price.InternalPrice || f.form.ExternalPrice != f.price.ExternalPrice {
+		errs = append(errs, f.db.UpdatePrice(origin, f.product.ID, f.form.PurchasePrice, f.form.InternalPrice, f.form.ExternalPrice))
+	}
+	if f.form.Upc != "" && f.form.Upc != f.product.Upc {
+		errs = append(errs, f.db.SetProductUpc(origin, f.product.ID, f.form.Upc))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (f *productForm) cancel(ctx ken.ComponentContext) bool {
+	if !f.allowed(ctx) {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.done = true
+	f.components.Unregister(f.buttonID("open"), f.buttonID("save"), f.buttonID("cancel"))
+
+	return f.update(ctx, &discordgo.MessageEmbed{Title: f.title(), Description: i18n.T(f.locale, "form.cancelled")}, []discordgo.MessageComponent{})
+}
+
+func (f *productForm) update(ctx ken.ComponentContext, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) bool {
+	err := ctx.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+	if err != nil {
+		log.Printf("error updating product form: %v", err)
+		return false
+	}
+
+	return true
+}
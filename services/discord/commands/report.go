@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"gostrecka/services/discord"
+	"gostrecka/services/export"
+	"gostrecka/services/i18n"
+	"gostrecka/services/reports"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+// reportFields is the most lines shown in the embed, the attachment has
+// all of them.
+const reportFields = 15
+
+type ReportCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.SlashCommand     = (*ReportCommand)(nil)
+	_ discord.AdminCommand = (*ReportCommand)(nil)
+)
+
+func (c *ReportCommand) Name() string {
+	return "report"
+}
+
+func (c *ReportCommand) Description() string {
+	return "Visar omsättning, varukostnad och marginal för en period"
+}
+
+func (c *ReportCommand) Version() string {
+	return "1.0.0"
+}
+
+func (c *ReportCommand) IsAdminOnly() bool {
+	return true
+}
+
+func (c *ReportCommand) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
+func (c *ReportCommand) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "group",
+			Description: "Vad rapporten delas upp efter, förvalt är produkt",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Produkt", Value: reports.GroupProduct},
+				{Name: "Kategori", Value: reports.GroupCategory},
+				{Name: "Dag", Value: reports.GroupDay},
+				{Name: "Månad", Value: reports.GroupMonth},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "from",
+			Description: "Första dagen i formatet ÅÅÅÅ-MM-DD, förvalt är början av månaden",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "to",
+			Description: "Sista dagen i formatet ÅÅÅÅ-MM-DD, förvalt är idag",
+			Required:    false,
+		},
+	}
+}
+
+func (c *ReportCommand) Run(ctx ken.Context) (err error) {
+	ctx.SetEphemeral(true)
+	if err = ctx.Defer(); err != nil {
+		return
+	}
+
+	locale := discord.Locale(ctx)
+	from, to, err := period(ctx)
+	if err != nil {
+		return ctx.FollowUpError(i18n.T(locale, "error.invalid_date"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	groupBy := reports.GroupProduct
+	if groupArg, ok := ctx.Options().GetByNameOptional("group"); ok {
+		groupBy = groupArg.StringValue()
+	}
+
+	db := discord.Database(ctx)
+	report, err := reports.Generate(db, from, to.AddDate(0, 0, 1), groupBy, locale)
+	if err != nil {
+		log.Printf("error generating report: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "report.failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	var buf bytes.Buffer
+	if err = export.Report(&buf, export.FormatCsv, report); err != nil {
+		log.Printf("error encoding report: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "report.failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       i18n.T(locale, "report.title", from.Format("2006-01-02"), to.Format("2006-01-02")),
+		Description: reportLine(locale, report.Total),
+	}
+
+	for i, line := range report.Lines {
+		if i == reportFields {
+			embed.Footer = &discordgo.MessageEmbedFooter{
+				Text: i18n.T(locale, "report.more", len(report.Lines)-reportFields),
+			}
+			break
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  line.Label,
+			Value: reportLine(locale, line),
+		})
+	}
+
+	if len(report.Lines) == 0 {
+		embed.Description = i18n.T(locale, "report.empty")
+	}
+
+	return ctx.FollowUp(true, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Files: []*discordgo.File{
+			{
+				Name:        fmt.Sprintf("rapport-%s-%s.csv", from.Format("2006-01-02"), to.Format("2006-01-02")),
+				ContentType: "text/csv",
+				Reader:      &buf,
+			},
+		},
+	}).Send().Error
+}
+
+func reportLine(locale i18n.Locale, line reports.Line) string {
+	return i18n.T(locale, "report.line", line.Quantity, line.Revenue, line.Cost, line.Margin, line.MarginPercent)
+}
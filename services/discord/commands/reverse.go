@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+// ReverseCommand lets admins reverse any transaction, such as one that was
+// disputed after the undo button stopped working.
+type ReverseCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.SlashCommand     = (*ReverseCommand)(nil)
+	_ discord.AdminCommand = (*ReverseCommand)(nil)
+)
+
+func (c *ReverseCommand) Name() string {
+	return "reverse"
+}
+
+func (c *ReverseCommand) Description() string {
+	return "Ångrar ett streck, till exempel ett som har bestridits"
+}
+
+func (c *ReverseCommand) Version() string {
+	return "1.0.0"
+}
+
+func (c *ReverseCommand) IsAdminOnly() bool {
+	return true
+}
+
+func (c *ReverseCommand) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
+func (c *ReverseCommand) Options() []*discordgo.ApplicationCommandOption {
+	var transactionMinValue float64 = 1.0
+
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "transaction",
+			Description: "Transaktionen att ångra",
+			Required:    true,
+			MinValue:    &transactionMinValue,
+		},
+	}
+}
+
+func (c *ReverseCommand) Run(ctx ken.Context) (err error) {
+	transactionId := ctx.Options().GetByName("transaction").IntValue()
+
+	db := discord.Database(ctx)
+	locale := discord.Locale(ctx)
+
+	transaction, err := db.GetTransaction(transactionId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ctx.RespondError(i18n.T(locale, "reverse.not_found", transactionId), i18n.T(locale, "error.title"))
+	}
+	if err != nil {
+		log.Printf("error getting transaction: %v", err)
+		return ctx.RespondError(i18n.T(locale, "error.internal"), i18n.T(locale, "error.title"))
+	}
+
+	// A transaction reversed between the lookup and here is gone either way.
+	err = db.ReverseTransaction(discord.Origin(ctx), transactionId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ctx.RespondError(i18n.T(locale, "reverse.not_found", transactionId), i18n.T(locale, "error.title"))
+	}
+	if err != nil {
+		log.Printf("error reversing transaction: %v", err)
+		return ctx.RespondError(i18n.T(locale, "reverse.failed"), i18n.T(locale, "error.title"))
+	}
+
+	return ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title: i18n.T(locale, "reverse.title"),
+		Description: i18n.T(locale, "reverse.done", transaction.Quantity, transaction.ProductName,
+			transaction.PricePaid*float64(transaction.Quantity), fmt.Sprintf("<@%s>", transaction.UserID)),
+		Footer: &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("#%d", transaction.ID)},
+	})
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+type SettingsCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.SlashCommand = (*SettingsCommand)(nil)
+)
+
+func (c *SettingsCommand) Name() string {
+	return "settings"
+}
+
+func (c *SettingsCommand) Description() string {
+	return "Visar eller ändrar dina inställningar"
+}
+
+func (c *SettingsCommand) Version() string {
+	return "1.0.0"
+}
+
+func (c *SettingsCommand) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
+func (c *SettingsCommand) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "reminders",
+			Description: "Ta emot påminnelser om skuld som direktmeddelande",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "language",
+			Description: "Språket boten svarar på",
+			Required:    false,
+			Choices:     languageChoices(),
+		},
+	}
+}
+
+// languageChoices offers the catalogue's languages by their own names, and
+// following the Discord client.
+func languageChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := []*discordgo.ApplicationCommandOptionChoice{
+		{Name: "Automatiskt", Value: "auto"},
+	}
+	for _, locale := range i18n.Locales {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: locale.Name(), Value: string(locale)})
+	}
+
+	return choices
+}
+
+func (c *SettingsCommand) Run(ctx ken.Context) (err error) {
+	ctx.SetEphemeral(true)
+
+	db := discord.Database(ctx)
+	locale := discord.Locale(ctx)
+
+	if _, _, err = db.GetUser(ctx.User().ID); err != nil {
+		return ctx.RespondError(i18n.T(locale, "error.user_not_found"), i18n.T(locale, "error.title"))
+	}
+
+	settings, err := db.GetUserSettings(ctx.User().ID)
+	if err != nil {
+		log.Printf("error getting settings: %v", err)
+		return ctx.RespondError(i18n.T(locale, "settings.get_failed"), i18n.T(locale, "error.title"))
+	}
+
+	remindersArg, remindersSupplied := ctx.Options().GetByNameOptional("reminders")
+	languageArg, languageSupplied := ctx.Options().GetByNameOptional("language")
+
+	if remindersSupplied || languageSupplied {
+		if remindersSupplied {
+			settings.DebtReminders = remindersArg.BoolValue()
+		}
+		if languageSupplied {
+			settings.Locale = ""
+			if language, ok := i18n.Parse(languageArg.StringValue()); ok {
+				settings.Locale = string(language)
+			}
+		}
+
+		if err = db.UpdateUserSettings(discord.Origin(ctx), settings); err != nil {
+			log.Printf("error updating settings: %v", err)
+			return ctx.RespondError(i18n.T(locale, "settings.save_failed"), i18n.T(locale, "error.title"))
+		}
+
+		// The response is in the language just picked.
+		locale = discord.Locale(ctx)
+	}
+
+	reminders := i18n.T(locale, "settings.off")
+	if settings.DebtReminders {
+		reminders = i18n.T(locale, "settings.on")
+	}
+
+	language := locale.Name()
+	if settings.Locale == "" {
+		language = i18n.T(locale, "settings.language_auto", language)
+	}
+
+	return ctx.RespondEmbed(&discordgo.MessageEmbed{
+		Title: i18n.T(locale, "settings.title"),
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   i18n.T(locale, "settings.reminders"),
+				Value:  reminders,
+				Inline: true,
+			},
+			{
+				Name:   i18n.T(locale, "settings.language"),
+				Value:  language,
+				Inline: true,
+			},
+		},
+	})
+}
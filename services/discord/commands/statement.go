@@ -0,0 +1,198 @@
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"gostrecka/models"
+	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
+	"gostrecka/utils"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+type StatementCommand struct {
+	discord.GuildScope
+}
+
+var (
+	_ ken.SlashCommand = (*StatementCommand)(nil)
+)
+
+func (c *StatementCommand) Name() string {
+	return "statement"
+}
+
+func (c *StatementCommand) Description() string {
+	return "Skickar ditt kontoutdrag för en månad som PDF"
+}
+
+func (c *StatementCommand) Version() string {
+	return "1.0.0"
+}
+
+func (c *StatementCommand) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
+func (c *StatementCommand) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "month",
+			Description: "Månad i formatet ÅÅÅÅ-MM, förvalt är innevarande månad",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "all",
+			Description: "Skapa kontoutdrag för alla användare (endast admin)",
+			Required:    false,
+		},
+	}
+}
+
+func (c *StatementCommand) Run(ctx ken.Context) (err error) {
+	month := time.Now().Format("2006-01")
+	if monthArg, ok := ctx.Options().GetByNameOptional("month"); ok {
+		month = monthArg.StringValue()
+	}
+
+	locale := discord.Locale(ctx)
+	from, err := time.ParseInLocation("2006-01", month, time.Local)
+	if err != nil {
+		return ctx.RespondError(i18n.T(locale, "statement.invalid_month"), i18n.T(locale, "error.title"))
+	}
+	to := from.AddDate(0, 1, 0)
+
+	if allArg, ok := ctx.Options().GetByNameOptional("all"); ok && allArg.BoolValue() {
+		if !discord.IsAdmin(ctx) {
+			return ctx.RespondError(i18n.T(locale, "error.forbidden"), i18n.T(locale, "error.title"))
+		}
+
+		return c.all(ctx, locale, month, from, to)
+	}
+
+	ctx.SetEphemeral(true)
+	if err = ctx.Defer(); err != nil {
+		return
+	}
+
+	db := discord.Database(ctx)
+	statement, err := db.GetStatement(ctx.User().ID, from, to)
+	if err != nil {
+		log.Printf("error getting statement: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "statement.get_failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	var buf bytes.Buffer
+	if err = utils.GenerateStatementPDF(statement, locale, &buf); err != nil {
+		log.Printf("error generating statement: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "statement.create_failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	session := ctx.GetSession()
+	channel, err := session.UserChannelCreate(ctx.User().ID)
+	if err == nil {
+		_, err = session.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+			Content: i18n.T(locale, "statement.dm", month),
+			Files: []*discordgo.File{
+				{
+					Name:        fmt.Sprintf("kontoutdrag-%s.pdf", month),
+					ContentType: "application/pdf",
+					Reader:      &buf,
+				},
+			},
+		})
+	}
+
+	if err != nil {
+		log.Printf("error sending statement: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "statement.send_failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	return ctx.FollowUpEmbed(&discordgo.MessageEmbed{
+		Description: i18n.T(locale, "statement.sent", month),
+	}).Send().Error
+}
+
+// all generates the statements of every user with activity or a balance
+// and uploads them as a single zip archive.
+func (c *StatementCommand) all(ctx ken.Context, locale i18n.Locale, month string, from time.Time, to time.Time) (err error) {
+	ctx.SetEphemeral(true)
+	if err = ctx.Defer(); err != nil {
+		return
+	}
+
+	db := discord.Database(ctx)
+	users, err := db.GetUsers()
+	if err != nil {
+		log.Printf("error getting users: %v", err)
+		return ctx.FollowUpError(i18n.T(locale, "product.user_failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	count := 0
+	for _, user := range users {
+		var statement models.Statement
+		statement, err = db.GetStatement(user.User.ID, from, to)
+		if err != nil {
+			log.Printf("error getting statement: %v", err)
+			return ctx.FollowUpError(i18n.T(locale, "statement.get_all_failed"), i18n.T(locale, "error.title")).Send().Error
+		}
+
+		if len(statement.Entries) == 0 && statement.OpeningBalance == 0 {
+			continue
+		}
+
+		file, err := archive.Create(fmt.Sprintf("%s-%s-%s.pdf", month, fileName(user.User.Name), user.User.ID))
+		if err == nil {
+			err = utils.GenerateStatementPDF(statement, locale, file)
+		}
+		if err != nil {
+			log.Printf("error generating statement: %v", err)
+			return ctx.FollowUpError(i18n.T(locale, "statement.create_all_failed"), i18n.T(locale, "error.title")).Send().Error
+		}
+
+		count++
+	}
+
+	if err = archive.Close(); err != nil {
+		return ctx.FollowUpError(i18n.T(locale, "statement.create_all_failed"), i18n.T(locale, "error.title")).Send().Error
+	}
+
+	if count == 0 {
+		return ctx.FollowUpEmbed(&discordgo.MessageEmbed{
+			Description: i18n.T(locale, "statement.none", month),
+		}).Send().Error
+	}
+
+	return ctx.FollowUp(true, &discordgo.WebhookParams{
+		Content: i18n.T(locale, "statement.all", count, month),
+		Files: []*discordgo.File{
+			{
+				Name:        fmt.Sprintf("kontoutdrag-%s.zip", month),
+				ContentType: "application/zip",
+				Reader:      &buf,
+			},
+		},
+	}).Send().Error
+}
+
+// fileName keeps only the characters of name that are safe in file names.
+func fileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -1,19 +1,27 @@
 package commands
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"gostrecka/internal/utils/static"
+	"gostrecka/models"
 	"gostrecka/services/database"
 	"gostrecka/services/discord"
+	"gostrecka/services/env"
+	"gostrecka/services/i18n"
 	"log"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
-	"github.com/wailsapp/wails/v3/pkg/application"
 	"github.com/zekrotja/ken"
 )
 
-type StreckaCommand struct{}
+type StreckaCommand struct {
+	discord.GuildScope
+}
 
 var (
 	_ ken.SlashCommand        = (*StreckaCommand)(nil)
@@ -77,58 +85,380 @@ func (c *StreckaCommand) Run(ctx ken.Context) (err error) {
 	user, userSupplied := ctx.Options().GetByNameOptional("user")
 	amountArg, amountSupplied := ctx.Options().GetByNameOptional("amount")
 
-	var amount int64
-	var response = "Streckar "
+	var amount int64 = 1
 	if amountSupplied {
 		amount = amountArg.IntValue()
-		response += fmt.Sprintf("%vst ", amountArg.IntValue())
-	} else {
-		response += "1st "
-		amount = 1
 	}
 
 	ProductID, err := strconv.ParseInt(productArg.StringValue(), 10, 64)
 	if err != nil {
 		log.Printf("error converting product Id to int64: %v", err)
-		return ctx.RespondError("Intern fel", "Fel")
+		locale := discord.Locale(ctx)
+		return ctx.RespondError(i18n.T(locale, "error.internal"), i18n.T(locale, "error.title"))
+	}
+
+	var discordUser *discordgo.User
+	if userSupplied {
+		discordUser = user.UserValue(ctx)
 	}
 
-	product, price, err := ctx.Get("database").(database.Database).GetProductIdent(ProductID)
+	return newStreckare(ctx).strecka(ctx, discordUser, ProductID, amount)
+}
+
+// streckare holds what streckande needs from the command context, so that
+// interactions without one, such as product picker selections, can strecka.
+type streckare struct {
+	db         database.Database
+	config     env.Config
+	components *ken.ComponentHandler
+}
+
+func newStreckare(ctx ken.Context) streckare {
+	return streckare{
+		db:         discord.Database(ctx),
+		config:     ctx.Get(static.DiConfig).(env.Config),
+		components: ctx.GetKen().Components(),
+	}
+}
+
+// strecka streckar amount of the product on discordUser, or on the invoking
+// user when nil, and responds with a receipt.
+func (s streckare) strecka(ctx ken.ContextResponder, discordUser *discordgo.User, productID int64, amount int64) (err error) {
+	locale := discord.InteractionLocale(s.db, s.config, ctx.GetEvent())
+
+	product, price, err := s.db.GetProductIdent(productID)
 	if err != nil {
 		fmt.Printf("error getting product: %v", err)
-		return ctx.RespondError("Produkten hittades inte", "Fel")
+		return ctx.RespondError(i18n.T(locale, "error.product_not_found"), i18n.T(locale, "error.title"))
 	}
 
-	response += fmt.Sprintf("%s (%.02f)", product.Name, price.InternalPrice*float64(amount))
-
-	var discordUser *discordgo.User
-	if userSupplied {
-		discordUser = user.UserValue(ctx)
-		response += fmt.Sprintf(" åt %s", discordUser.Mention())
+	response := i18n.T(locale, "strecka.receipt", amount, product.Name, price.InternalPrice*float64(amount))
+	if discordUser != nil {
+		response = i18n.T(locale, "strecka.for_user", response, discordUser.Mention())
 	} else {
 		discordUser = ctx.User()
-		response += " åt dig"
+		response = i18n.T(locale, "strecka.for_self", response)
+	}
+
+	userStruct, _, err := s.db.GetUser(discordUser.ID)
+
+	if err != nil {
+		ctx.Respond(&discordgo.InteractionResponse{Type: discordgo.InteractionResponseChannelMessageWithSource, Data: &discordgo.InteractionResponseData{Content: i18n.T(locale, "error.self_unregistered")}})
+		return
+	}
+
+	transactionId, err := s.db.Strecka(discord.Origin(ctx), userStruct, productID, amount)
+	if err != nil {
+		log.Printf("error strecka: %v", err)
+		return ctx.RespondError(i18n.T(locale, "strecka.failed"), i18n.T(locale, "error.title"))
+	}
+
+	if err = ctx.Defer(); err != nil {
+		return
+	}
+
+	r := &receipt{
+		db:            s.db,
+		config:        s.config,
+		components:    s.components,
+		session:       ctx.GetSession(),
+		locale:        locale,
+		actorID:       ctx.User().ID,
+		user:          userStruct,
+		productID:     productID,
+		amount:        amount,
+		transactionID: transactionId,
+		text:          response,
+	}
+
+	return r.send(ctx)
+}
+
+// streckaButtonsTimeout is how long the buttons on a strecka response
+// work. Responses can only be edited through their interaction for 15
+// minutes, so the buttons are removed before then.
+const streckaButtonsTimeout = 14 * time.Minute
+
+// receipt is a strecka response with buttons to undo, repeat or dispute
+// the strecka. A click arriving while another is handled is dropped, so a
+// double click never undoes, repeats or disputes twice.
+type receipt struct {
+	mu sync.Mutex
+
+	db         database.Database
+	config     env.Config
+	components *ken.ComponentHandler
+	session    *discordgo.Session
+	// locale is the actor's language, which the receipt is written in.
+	locale i18n.Locale
+
+	// actorID is the user who streckade, user who it was streckat on.
+	actorID       string
+	user          models.User
+	productID     int64
+	amount        int64
+	transactionID int64
+	text          string
+
+	interaction  *discordgo.Interaction
+	messageID    string
+	undoDeadline time.Time
+	undoneBy     string
+	disputedBy   string
+	closed       bool
+}
+
+// id returns the custom id of a button. It is keyed on the interaction the
+// receipt answers, since transaction ids repeat across guild databases.
+func (r *receipt) id(button string) string {
+	return fmt.Sprintf("strecka-%s-%s", button, r.interaction.ID)
+}
+
+// send posts the receipt as the follow up of a deferred interaction and
+// starts handling its buttons.
+func (r *receipt) send(ctx ken.ContextResponder) error {
+	undoWindow, err := time.ParseDuration(r.config.Strecka.UndoWindow)
+	if err != nil {
+		log.Printf("error parsing undo window: %v", err)
+		undoWindow = 0
+	}
+	undoWindow = min(undoWindow, streckaButtonsTimeout)
+
+	r.interaction = ctx.GetEvent().Interaction
+	r.undoDeadline = time.Now().Add(undoWindow)
+
+	fum := ctx.FollowUp(true, &discordgo.WebhookParams{Content: r.content()}).AddComponents(func(cb *ken.ComponentBuilder) {
+		cb.AddActionsRow(func(b ken.ComponentAssembler) {
+			for _, button := range r.buttons() {
+				b.Add(button, r.handle)
+			}
+		})
+	}).Send()
+	if fum.Error != nil {
+		return fum.Error
+	}
+
+	r.messageID = fum.ID
+
+	if undoWindow > 0 {
+		time.AfterFunc(undoWindow, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if !r.closed {
+				r.edit()
+			}
+		})
+	}
+	time.AfterFunc(streckaButtonsTimeout, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.close()
+		r.edit()
+	})
+
+	return nil
+}
+
+func (r *receipt) content() string {
+	content := r.text
+	if r.undoneBy != "" {
+		content = fmt.Sprintf("~~%s~~\n%s", content, i18n.T(r.locale, "strecka.undone_by", "<@"+r.undoneBy+">"))
+	}
+	if r.disputedBy != "" {
+		content += "\n" + i18n.T(r.locale, "strecka.disputed_by", "<@"+r.disputedBy+">")
+	}
+
+	return content
+}
+
+// buttons returns the buttons that still apply to the receipt.
+func (r *receipt) buttons() []discordgo.Button {
+	if r.closed || r.undoneBy != "" {
+		return nil
+	}
+
+	var buttons []discordgo.Button
+	if time.Now().Before(r.undoDeadline) {
+		buttons = append(buttons, discordgo.Button{CustomID: r.id("undo"), Label: i18n.T(r.locale, "strecka.undo"), Style: discordgo.DangerButton})
 	}
+	buttons = append(buttons, discordgo.Button{CustomID: r.id("repeat"), Label: "+1", Style: discordgo.SecondaryButton})
+	if r.user.ID != r.actorID && r.disputedBy == "" {
+		buttons = append(buttons, discordgo.Button{CustomID: r.id("dispute"), Label: i18n.T(r.locale, "strecka.dispute"), Style: discordgo.SecondaryButton})
+	}
+
+	return buttons
+}
 
-	db := ctx.Get("database").(database.Database)
-	userStruct, _, err := db.GetUser(discordUser.ID)
+func (r *receipt) messageComponents() []discordgo.MessageComponent {
+	buttons := r.buttons()
+	if len(buttons) == 0 {
+		return []discordgo.MessageComponent{}
+	}
 
+	row := discordgo.ActionsRow{}
+	for _, button := range buttons {
+		row.Components = append(row.Components, button)
+	}
+
+	return []discordgo.MessageComponent{row}
+}
+
+// edit brings the message up to date without a click to respond to.
+func (r *receipt) edit() {
+	content := r.content()
+	components := r.messageComponents()
+	_, err := r.session.FollowupMessageEdit(r.interaction, r.messageID, &discordgo.WebhookEdit{
+		Content:    &content,
+		Components: &components,
+	})
 	if err != nil {
-		ctx.Respond(&discordgo.InteractionResponse{Type: discordgo.InteractionResponseChannelMessageWithSource, Data: &discordgo.InteractionResponseData{Content: "Du är inte registrerad i systemet\nRegistrera med /user create"}})
+		log.Printf("error editing strecka response: %v", err)
+	}
+}
+
+// close stops handling clicks, the buttons are removed by the next update
+// of the message.
+func (r *receipt) close() {
+	if r.closed {
 		return
 	}
 
-	_ = db.Strecka(userStruct, ProductID, amount)
+	r.closed = true
+	r.components.Unregister(r.id("undo"), r.id("repeat"), r.id("dispute"))
+}
+
+func (r *receipt) handle(ctx ken.ComponentContext) bool {
+	if !r.mu.TryLock() {
+		// Acknowledge the click without changing the message.
+		ctx.Respond(&discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+		return false
+	}
+	defer r.mu.Unlock()
+
+	var err error
+	switch ctx.GetData().CustomID {
+	case r.id("undo"):
+		err = r.undo(ctx)
+	case r.id("repeat"):
+		err = r.repeat(ctx)
+	case r.id("dispute"):
+		err = r.dispute(ctx)
+	}
 
-	ctx.Respond(&discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
+	if err != nil {
+		log.Printf("error handling strecka button: %v", err)
+		return false
+	}
+
+	return true
+}
+
+// update responds to a click by bringing the message up to date.
+func (r *receipt) update(ctx ken.ComponentContext) error {
+	return ctx.Respond(&discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
 		Data: &discordgo.InteractionResponseData{
-			Content: response,
+			Content:    r.content(),
+			Components: r.messageComponents(),
 		},
 	})
+}
+
+// deny tells only the user who clicked why nothing happened, in their
+// language.
+func (r *receipt) deny(ctx ken.ComponentContext, key string) error {
+	ctx.SetEphemeral(true)
+	return ctx.RespondMessage(i18n.T(discord.InteractionLocale(r.db, r.config, ctx.GetEvent()), key))
+}
 
-	desktop := ctx.Get("app").(*application.App)
-	desktop.Events.Emit(&application.WailsEvent{Name: "transaction_updated", Sender: static.DiDesktop})
+func (r *receipt) undo(ctx ken.ComponentContext) error {
+	if ctx.User().ID != r.actorID && !discord.IsAdminInteraction(r.config, ctx.GetEvent()) {
+		return r.deny(ctx, "strecka.undo_forbidden")
+	}
+	if r.undoneBy != "" {
+		return r.update(ctx)
+	}
+	if time.Now().After(r.undoDeadline) {
+		return r.deny(ctx, "strecka.undo_too_late")
+	}
+
+	// A transaction that is already gone was reversed some other way,
+	// which is what the click asked for.
+	err := r.db.ReverseTransaction(discord.Origin(ctx), r.transactionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return errors.Join(err, r.deny(ctx, "strecka.undo_failed"))
+	}
+
+	r.undoneBy = ctx.User().ID
+	r.close()
+
+	return r.update(ctx)
+}
+
+func (r *receipt) repeat(ctx ken.ComponentContext) error {
+	if ctx.User().ID != r.actorID {
+		return r.deny(ctx, "strecka.repeat_forbidden")
+	}
+
+	transactionId, err := r.db.Strecka(discord.Origin(ctx), r.user, r.productID, r.amount)
+	if err != nil {
+		return errors.Join(err, r.deny(ctx, "strecka.failed"))
+	}
+
+	if err = ctx.Defer(); err != nil {
+		return err
+	}
+
+	next := &receipt{
+		db:            r.db,
+		config:        r.config,
+		components:    r.components,
+		session:       r.session,
+		locale:        r.locale,
+		actorID:       r.actorID,
+		user:          r.user,
+		productID:     r.productID,
+		amount:        r.amount,
+		transactionID: transactionId,
+		text:          r.text,
+	}
+
+	return next.send(ctx)
+}
+
+func (r *receipt) dispute(ctx ken.ComponentContext) error {
+	if ctx.User().ID != r.user.ID {
+		return r.deny(ctx, "strecka.dispute_forbidden")
+	}
+	if r.disputedBy != "" || r.undoneBy != "" {
+		return r.update(ctx)
+	}
+
+	err := r.db.DisputeTransaction(discord.Origin(ctx), r.transactionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return r.deny(ctx, "strecka.dispute_gone")
+	}
+	if err != nil {
+		return errors.Join(err, r.deny(ctx, "strecka.dispute_failed"))
+	}
+
+	r.disputedBy = ctx.User().ID
+
+	if channel := r.config.DisputeChannel(ctx.GetEvent().GuildID); channel != "" {
+		// The dispute channel is read by admins, not by the user.
+		locale := i18n.Resolve(r.config.Locale)
+		_, err = r.session.ChannelMessageSendEmbed(channel, &discordgo.MessageEmbed{
+			Title:       i18n.T(locale, "strecka.dispute_title"),
+			Description: i18n.T(locale, "strecka.dispute_post", ctx.User().Mention(), "<@"+r.actorID+">"),
+			Fields: []*discordgo.MessageEmbedField{
+				{Name: i18n.T(locale, "strecka.dispute_receipt"), Value: r.text},
+			},
+			Footer: &discordgo.MessageEmbedFooter{Text: i18n.T(locale, "strecka.dispute_reverse", r.transactionID)},
+		})
+		if err != nil {
+			log.Printf("error posting dispute: %v", err)
+		}
+	}
 
-	return
+	return r.update(ctx)
 }
@@ -2,16 +2,17 @@ package commands
 
 import (
 	"database/sql"
-	"fmt"
-	"gostrecka/internal/utils/static"
-	"gostrecka/services/database"
+	"gostrecka/services/discord"
+	"gostrecka/services/i18n"
 	"log"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/zekrotja/ken"
 )
 
-type UserCommand struct{}
+type UserCommand struct {
+	discord.GuildScope
+}
 
 var (
 	_ ken.SlashCommand = (*UserCommand)(nil)
@@ -44,7 +45,7 @@ func (c *UserCommand) Options() []*discordgo.ApplicationCommandOption {
 				{
 					Type:         discordgo.ApplicationCommandOptionUser,
 					Name:         "user",
-					Description:  "User to create for",
+					Description:  "Användaren att skapa ett konto åt",
 					Required:     false,
 					Autocomplete: true,
 				},
@@ -79,14 +80,15 @@ func (c *UserCommand) create(ctx ken.SubCommandContext) (err error) {
 		account = ctx.User()
 
 	}
-	db := ctx.Get(static.DiDatabase).(database.Database)
+	db := discord.Database(ctx)
+	locale := discord.Locale(ctx)
 
 	match, _, err := db.GetUser(account.ID)
 	if err != nil || match.ID != "" {
 		log.Printf("Error: %v", err)
 		if err != sql.ErrNoRows {
 			err = ctx.FollowUpEmbed(&discordgo.MessageEmbed{
-				Description: "User already exists",
+				Description: i18n.T(locale, "user.exists"),
 			}).Send().Error
 
 			if err != nil {
@@ -97,18 +99,19 @@ func (c *UserCommand) create(ctx ken.SubCommandContext) (err error) {
 		}
 	}
 
-	err = db.CreateUser(account.ID, account.GlobalName)
+	err = db.CreateUser(discord.Origin(ctx), account.ID, account.GlobalName)
 
 	if err != nil {
+		log.Printf("error creating user: %v", err)
 		err = ctx.FollowUpEmbed(&discordgo.MessageEmbed{
-			Description: fmt.Sprintf("Failed to create user: %s", err.Error()),
+			Description: i18n.T(locale, "user.create_failed"),
 			Color:       1,
 		}).Send().Error
 		return
 	}
 
 	err = ctx.FollowUpEmbed(&discordgo.MessageEmbed{
-		Description: fmt.Sprintf("User %s created!", account.Mention()),
+		Description: i18n.T(locale, "user.created", account.Mention()),
 	}).Send().Error
 	return
 }
@@ -0,0 +1,47 @@
+package discord
+
+import (
+	"gostrecka/internal/utils/static"
+	"gostrecka/services/database"
+
+	"github.com/zekrotja/ken"
+)
+
+// Database returns the database of the guild the interaction was made in.
+func Database(ctx ken.Context) database.Database {
+	return ctx.Get(static.DiGuilds).(*database.Guilds).Get(ctx.GetEvent().GuildID)
+}
+
+// DefaultDatabase reports whether the interaction was made where the
+// default database is used, which commands that act on it rather than on a
+// guild's own must check.
+func DefaultDatabase(ctx ken.Context) bool {
+	return !ctx.Get(static.DiGuilds).(*database.Guilds).Has(ctx.GetEvent().GuildID)
+}
+
+// GuildScope is embedded in commands so ken registers them in a guild
+// instead of globally, which takes up to an hour to reach the clients.
+type GuildScope struct {
+	guild string
+}
+
+var _ ken.GuildScopedCommand = (*GuildScope)(nil)
+
+func (s *GuildScope) Guild() string {
+	return s.guild
+}
+
+func (s *GuildScope) setGuild(guild string) {
+	s.guild = guild
+}
+
+// ScopeCommands makes ken register the commands embedding GuildScope in
+// guild. ken registers every command in a single guild, the others need
+// RegisterCommands.
+func ScopeCommands(guild string, cmds ...ken.Command) {
+	for _, cmd := range cmds {
+		if scoped, ok := cmd.(interface{ setGuild(string) }); ok {
+			scoped.setGuild(guild)
+		}
+	}
+}
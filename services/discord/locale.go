@@ -0,0 +1,166 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"gostrecka/internal/utils/static"
+	"gostrecka/services/database"
+	"gostrecka/services/env"
+	"gostrecka/services/i18n"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+	"github.com/zekrotja/ken/store"
+)
+
+// Locale returns the language to respond to the interaction in: the one
+// the user has picked with /settings, otherwise their Discord client's and
+// last the configured one.
+func Locale(ctx ken.Context) i18n.Locale {
+	return InteractionLocale(Database(ctx), ctx.Get(static.DiConfig).(env.Config), ctx.GetEvent())
+}
+
+// InteractionLocale is Locale for interactions without a command context,
+// such as button clicks.
+func InteractionLocale(db database.Database, config env.Config, event *discordgo.InteractionCreate) i18n.Locale {
+	user := event.User
+	if event.Member != nil {
+		user = event.Member.User
+	}
+
+	var preference string
+	if user != nil {
+		settings, err := db.GetUserSettings(user.ID)
+		if err != nil {
+			log.Printf("error getting settings: %v", err)
+		}
+		preference = settings.Locale
+	}
+
+	return i18n.Resolve(preference, string(event.Locale), config.Locale)
+}
+
+// discordLocales are the Discord client languages each language of the
+// catalogue is shown to.
+var discordLocales = map[i18n.Locale][]discordgo.Locale{
+	i18n.Swedish: {discordgo.Swedish},
+	i18n.English: {discordgo.EnglishUS, discordgo.EnglishGB},
+}
+
+// localizations returns the translations of key for the Discord client
+// languages, or nil when there are none.
+func localizations(key string) map[discordgo.Locale]string {
+	var translations map[discordgo.Locale]string
+	for _, locale := range i18n.Locales {
+		message, ok := i18n.Lookup(locale, key)
+		if !ok {
+			continue
+		}
+
+		if translations == nil {
+			translations = map[discordgo.Locale]string{}
+		}
+		for _, discordLocale := range discordLocales[locale] {
+			translations[discordLocale] = message
+		}
+	}
+
+	return translations
+}
+
+// LocalizeCommand adds the catalogue's translations of the command's name,
+// description, options and choices to it.
+func LocalizeCommand(cmd *discordgo.ApplicationCommand) {
+	if translations := localizations("command_name." + cmd.Name); translations != nil {
+		cmd.NameLocalizations = &translations
+	}
+	if cmd.Type == discordgo.ChatApplicationCommand {
+		if translations := localizations("command." + cmd.Name); translations != nil {
+			cmd.DescriptionLocalizations = &translations
+		}
+	}
+
+	localizeOptions("command."+cmd.Name, cmd.Options)
+}
+
+func localizeOptions(prefix string, options []*discordgo.ApplicationCommandOption) {
+	for _, option := range options {
+		key := prefix + "." + option.Name
+		option.DescriptionLocalizations = localizations(key)
+
+		for _, choice := range option.Choices {
+			choice.NameLocalizations = localizations(key + "=" + fmt.Sprint(choice.Value))
+		}
+
+		localizeOptions(key, option.Options)
+	}
+}
+
+// CommandLocalizer is a ken command store that adds the catalogue's
+// translations to the commands once ken has registered them, as ken leaves
+// them out. Like ken without a store, it remembers no commands between
+// runs.
+type CommandLocalizer struct {
+	Session *discordgo.Session
+	// Guild is where ken registers the commands, see ScopeCommands. The
+	// commands are global when empty.
+	Guild string
+}
+
+var _ store.CommandStore = (*CommandLocalizer)(nil)
+
+func (l *CommandLocalizer) Load() (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+// Store is called by ken with the ids of the commands it has registered.
+func (l *CommandLocalizer) Store(commands map[string]string) error {
+	appID := l.Session.State.User.ID
+
+	if l.Guild != "" {
+		// ken overwrites the commands it knows the ids of globally when it
+		// is ready again after a reconnect, so it must not keep them.
+		defer clear(commands)
+	}
+
+	var errs []error
+	localized := make([]*discordgo.ApplicationCommand, 0, len(commands))
+	for name, id := range commands {
+		cmd, err := l.Session.ApplicationCommand(appID, l.Guild, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not get command %s: %w", name, err))
+			continue
+		}
+
+		LocalizeCommand(cmd)
+		cmd.ID, cmd.ApplicationID, cmd.GuildID, cmd.Version = "", "", "", ""
+		localized = append(localized, cmd)
+	}
+
+	// Overwriting with some of the commands missing would remove them.
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if _, err := l.Session.ApplicationCommandBulkOverwrite(appID, l.Guild, localized); err != nil {
+		return fmt.Errorf("could not localize commands: %w", err)
+	}
+
+	return nil
+}
+
+// RegisterCommands registers the commands with the catalogue's
+// translations in guild, replacing the ones registered there before.
+func RegisterCommands(session *discordgo.Session, guild string, commands ken.CommandInfoList) error {
+	localized := make([]*discordgo.ApplicationCommand, 0, len(commands))
+	for _, info := range commands {
+		cmd := *info.ApplicationCommand
+		cmd.Version = ""
+		LocalizeCommand(&cmd)
+		localized = append(localized, &cmd)
+	}
+
+	_, err := session.ApplicationCommandBulkOverwrite(session.State.User.ID, guild, localized)
+	return err
+}
@@ -0,0 +1,17 @@
+package discord
+
+import (
+	"gostrecka/models"
+
+	"github.com/zekrotja/ken"
+)
+
+// Origin describes the interaction as the origin of a database mutation,
+// with the invoking user as actor and the interaction ID as reference.
+func Origin(ctx ken.ContextResponder) models.Origin {
+	return models.Origin{
+		Source:    models.OriginDiscord,
+		ActorID:   ctx.User().ID,
+		Reference: ctx.GetEvent().ID,
+	}
+}
@@ -0,0 +1,55 @@
+package discord
+
+import (
+	"gostrecka/internal/utils/static"
+	"gostrecka/services/env"
+	"gostrecka/services/i18n"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/zekrotja/ken"
+)
+
+// IsAdmin reports whether the user invoking the interaction may run
+// administrative commands. Guild members with the Administrator permission
+// are always allowed, other users must be listed in the config.
+func IsAdmin(ctx ken.Context) bool {
+	return IsAdminInteraction(ctx.Get(static.DiConfig).(env.Config), ctx.GetEvent())
+}
+
+// IsAdminInteraction is IsAdmin for interactions without a command context,
+// such as button clicks.
+func IsAdminInteraction(config env.Config, event *discordgo.InteractionCreate) bool {
+	if event.Member != nil && event.Member.Permissions&discordgo.PermissionAdministrator != 0 {
+		return true
+	}
+
+	user := event.User
+	if event.Member != nil {
+		user = event.Member.User
+	}
+
+	return user != nil && slices.Contains(config.Admins, user.ID)
+}
+
+// AdminCommand is implemented by commands only admins may run. /help lists
+// them separately and AdminMiddleware refuses them to everyone else.
+type AdminCommand interface {
+	IsAdminOnly() bool
+}
+
+// AdminMiddleware refuses admin commands to users who are not admins
+// before the commands run.
+type AdminMiddleware struct{}
+
+var _ ken.MiddlewareBefore = (*AdminMiddleware)(nil)
+
+func (m *AdminMiddleware) Before(ctx *ken.Ctx) (next bool, err error) {
+	cmd, ok := ctx.GetCommand().(AdminCommand)
+	if !ok || !cmd.IsAdminOnly() || IsAdmin(ctx) {
+		return true, nil
+	}
+
+	locale := Locale(ctx)
+	return false, ctx.RespondError(i18n.T(locale, "error.forbidden"), i18n.T(locale, "error.title"))
+}
@@ -1,11 +1,13 @@
 package env
 
 import (
+	"cmp"
 	"fmt"
 	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/adrg/xdg"
@@ -14,9 +16,303 @@ import (
 )
 
 type Config struct {
-	DiscordToken string `yaml:"discord_token" envconfig:"DISCORD_TOKEN" required:"true"`
-	Guild        string `yaml:"guild" envconfig:"GUILD" required:"false"`
-	DbUrl        string `yaml:"db_url" envconfig:"DB_URL" required:"true"`
+	DiscordToken string            `yaml:"discord_token" envconfig:"DISCORD_TOKEN" required:"true"`
+	Guild        string            `yaml:"guild" envconfig:"GUILD" required:"false"`
+	Guilds       []GuildConfig     `yaml:"guilds" envconfig:"-"`
+	DbUrl        string            `yaml:"db_url" envconfig:"DB_URL" required:"true"`
+	Admins       []string          `yaml:"admins" envconfig:"ADMINS" required:"false"`
+	Headless     bool              `yaml:"headless" envconfig:"HEADLESS" required:"false"`
+	Backup       BackupConfig      `yaml:"backup" envconfig:"BACKUP"`
+	Api          ApiConfig         `yaml:"api" envconfig:"API"`
+	Metrics      MetricsConfig     `yaml:"metrics" envconfig:"METRICS"`
+	Swish        SwishConfig       `yaml:"swish" envconfig:"SWISH"`
+	Reminders    RemindersConfig   `yaml:"reminders" envconfig:"REMINDERS"`
+	Sie          SieConfig         `yaml:"sie" envconfig:"SIE"`
+	Leaderboard  LeaderboardConfig `yaml:"leaderboard" envconfig:"LEADERBOARD"`
+	Strecka      StreckaConfig     `yaml:"strecka" envconfig:"STRECKA"`
+	Log          LogConfig         `yaml:"log" envconfig:"LOG"`
+	Badges       BadgesConfig      `yaml:"badges" envconfig:"BADGES"`
+	// Locale is the language of the kiosk, and of the bot for users who
+	// have not picked one and whose Discord client is in a language the
+	// bot does not have.
+	Locale string `yaml:"locale" envconfig:"LOCALE"`
+	// Jobs maps scheduled job names to cron expressions, e.g. "0 18 * * *"
+	// or "@every 6h". Jobs with an empty schedule only run when triggered.
+	Jobs map[string]string `yaml:"jobs" envconfig:"JOBS"`
+}
+
+// GuildConfig is a further guild the bot serves, with users, products and
+// a ledger of its own. Guild keeps the database at DbUrl, which is also the
+// one the kiosk, the API, the command line and direct messages use. The
+// scheduled jobs run against every database.
+type GuildConfig struct {
+	ID string `yaml:"id"`
+	// DbUrl is the guild's database. The guild shares the one at
+	// Config.DbUrl when empty.
+	DbUrl string `yaml:"db_url"`
+	// DisputeChannel replaces Strecka.DisputeChannel for the guild, whose
+	// disputes are only recorded in the audit log when empty.
+	DisputeChannel string `yaml:"dispute_channel"`
+	// LogChannel replaces Log.Channel for the guild.
+	LogChannel string `yaml:"log_channel"`
+	// BadgeChannel replaces Badges.Channel for the guild.
+	BadgeChannel string `yaml:"badge_channel"`
+	// ReminderChannel replaces Reminders.Channel for the guild.
+	ReminderChannel string `yaml:"reminder_channel"`
+	// Swish replaces Swish for the guild, whose members are shown no QR
+	// codes when it has no payee.
+	Swish SwishConfig `yaml:"swish"`
+	// Sie replaces Sie for the guild. Fields left empty take the defaults
+	// rather than the values of Sie.
+	Sie SieConfig `yaml:"sie"`
+}
+
+// GuildIDs returns the guilds the bot serves, Guild first. Commands are
+// registered in each of them, or globally when there are none.
+func (c Config) GuildIDs() (ids []string) {
+	if c.Guild != "" {
+		ids = append(ids, c.Guild)
+	}
+	for _, guild := range c.Guilds {
+		if guild.ID != "" && !slices.Contains(ids, guild.ID) {
+			ids = append(ids, guild.ID)
+		}
+	}
+
+	return
+}
+
+// DisputeChannel returns the channel transactions disputed in a guild are
+// posted to.
+func (c Config) DisputeChannel(guildId string) string {
+	if guild, ok := c.guild(guildId); ok {
+		return guild.DisputeChannel
+	}
+
+	return c.Strecka.DisputeChannel
+}
+
+// LogChannel returns the channel changes to the ledger of a guild are
+// posted to.
+func (c Config) LogChannel(guildId string) string {
+	if guild, ok := c.guild(guildId); ok {
+		return guild.LogChannel
+	}
+
+	return c.Log.Channel
+}
+
+// BadgeChannel returns the channel badges unlocked in a guild are
+// announced in.
+func (c Config) BadgeChannel(guildId string) string {
+	if guild, ok := c.guild(guildId); ok {
+		return guild.BadgeChannel
+	}
+
+	return c.Badges.Channel
+}
+
+// ReminderChannel returns the channel a summary of the debt reminders sent
+// to the members of a guild is posted to.
+func (c Config) ReminderChannel(guildId string) string {
+	if guild, ok := c.guild(guildId); ok {
+		return guild.ReminderChannel
+	}
+
+	return c.Reminders.Channel
+}
+
+// SwishOf returns where the members of a guild send payments.
+func (c Config) SwishOf(guildId string) SwishConfig {
+	if guild, ok := c.guild(guildId); ok {
+		return guild.Swish
+	}
+
+	return c.Swish
+}
+
+// SieOf returns how the ledger of a guild is exported to SIE.
+func (c Config) SieOf(guildId string) SieConfig {
+	guild, ok := c.guild(guildId)
+	if !ok {
+		return c.Sie
+	}
+
+	sie, defaults := guild.Sie, DefaultConfig().Sie
+	if sie.FiscalYearStart == 0 {
+		sie.FiscalYearStart = defaults.FiscalYearStart
+	}
+	sie.Series = cmp.Or(sie.Series, defaults.Series)
+	sie.Accounts.Cash = cmp.Or(sie.Accounts.Cash, defaults.Accounts.Cash)
+	sie.Accounts.Bank = cmp.Or(sie.Accounts.Bank, defaults.Accounts.Bank)
+	sie.Accounts.Members = cmp.Or(sie.Accounts.Members, defaults.Accounts.Members)
+	sie.Accounts.InternalSales = cmp.Or(sie.Accounts.InternalSales, defaults.Accounts.InternalSales)
+	sie.Accounts.ExternalSales = cmp.Or(sie.Accounts.ExternalSales, defaults.Accounts.ExternalSales)
+	sie.Accounts.Purchases = cmp.Or(sie.Accounts.Purchases, defaults.Accounts.Purchases)
+	sie.Accounts.WriteOffs = cmp.Or(sie.Accounts.WriteOffs, defaults.Accounts.WriteOffs)
+
+	return sie
+}
+
+// DbUrlOf returns the database of a guild, the one at DbUrl when guildId
+// is empty or the guild has none of its own.
+func (c Config) DbUrlOf(guildId string) (string, error) {
+	if guildId == "" {
+		return c.DbUrl, nil
+	}
+
+	guild, ok := c.guild(guildId)
+	if !ok && guildId != c.Guild {
+		return "", fmt.Errorf("guild %s is not configured", guildId)
+	}
+	if guild.DbUrl == "" {
+		return c.DbUrl, nil
+	}
+
+	return guild.DbUrl, nil
+}
+
+func (c Config) guild(id string) (GuildConfig, bool) {
+	for _, guild := range c.Guilds {
+		if guild.ID == id {
+			return guild, true
+		}
+	}
+
+	return GuildConfig{}, false
+}
+
+// BackupConfig controls the periodic database snapshots.
+type BackupConfig struct {
+	// Dir is where snapshots are written.
+	Dir string `yaml:"dir" envconfig:"DIR"`
+	// Retention is the number of snapshots to keep, older ones are removed.
+	Retention int `yaml:"retention" envconfig:"RETENTION"`
+}
+
+// ApiConfig controls the optional HTTP API.
+type ApiConfig struct {
+	Enabled bool       `yaml:"enabled" envconfig:"ENABLED"`
+	Listen  string     `yaml:"listen" envconfig:"LISTEN"`
+	Tokens  []ApiToken `yaml:"tokens" envconfig:"-"`
+}
+
+// ApiToken grants a client access to the endpoints covered by its scopes.
+type ApiToken struct {
+	Name   string   `yaml:"name"`
+	Token  string   `yaml:"token"`
+	Scopes []string `yaml:"scopes"`
+}
+
+// MetricsConfig controls the optional Prometheus endpoint.
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled" envconfig:"ENABLED"`
+	Listen  string `yaml:"listen" envconfig:"LISTEN"`
+}
+
+// SwishConfig controls the Swish payment QR codes offered to users in debt.
+type SwishConfig struct {
+	// Payee is the Swish number payments are sent to. An empty value
+	// disables the QR codes.
+	Payee string `yaml:"payee" envconfig:"PAYEE"`
+	// Message is put in front of the user reference in the payment
+	// message.
+	Message string `yaml:"message" envconfig:"MESSAGE"`
+}
+
+// Enabled reports whether a payee has been configured.
+func (c SwishConfig) Enabled() bool {
+	return c.Payee != ""
+}
+
+// Reference returns the payment message identifying userId, so payments
+// can be matched to users.
+func (c SwishConfig) Reference(userId string) string {
+	return strings.TrimSpace(c.Message + " " + userId)
+}
+
+// RemindersConfig controls the debt reminders sent to users.
+type RemindersConfig struct {
+	// Threshold is the debt in kronor above which users are reminded.
+	Threshold float64 `yaml:"threshold" envconfig:"THRESHOLD"`
+	// Cooldown is the least time between two reminders to the same user.
+	Cooldown string `yaml:"cooldown" envconfig:"COOLDOWN"`
+	// Channel is the admin channel a summary of each run is posted to.
+	Channel string `yaml:"channel" envconfig:"CHANNEL"`
+}
+
+// LeaderboardConfig sets the leaderboard shown on the kiosk and how
+// leaderboard windows are measured.
+type LeaderboardConfig struct {
+	// Window is the kiosk's window: tonight, week, term or all.
+	Window string `yaml:"window" envconfig:"WINDOW"`
+	// Metric is what the kiosk ranks by: units or spend.
+	Metric string `yaml:"metric" envconfig:"METRIC"`
+	// ProductID limits the kiosk's leaderboard to one product, zero for all.
+	ProductID int64 `yaml:"product_id" envconfig:"PRODUCT_ID"`
+	// Category limits the kiosk's leaderboard to one category, empty for all.
+	Category string `yaml:"category" envconfig:"CATEGORY"`
+	// Tonight is how far back the tonight window reaches.
+	Tonight string `yaml:"tonight" envconfig:"TONIGHT"`
+	// Change is how far back ranks are compared to show movement.
+	Change string `yaml:"change" envconfig:"CHANGE"`
+	// TermStarts are the days terms start on each year, as MM-DD.
+	TermStarts []string `yaml:"term_starts" envconfig:"TERM_STARTS"`
+}
+
+// StreckaConfig controls the buttons on the bot's strecka responses.
+type StreckaConfig struct {
+	// UndoWindow is how long a strecka can be undone from its response.
+	UndoWindow string `yaml:"undo_window" envconfig:"UNDO_WINDOW"`
+	// DisputeChannel is the admin channel disputed transactions are
+	// posted to. Disputes are only recorded in the audit log when empty.
+	DisputeChannel string `yaml:"dispute_channel" envconfig:"DISPUTE_CHANNEL"`
+}
+
+// LogConfig controls the channel every strecka, stock addition, price
+// change, payment and reversal is posted to as it happens.
+type LogConfig struct {
+	// Channel is where the changes are posted, nothing is when empty.
+	Channel string `yaml:"channel" envconfig:"CHANNEL"`
+	// Batch is how long changes are collected before they are posted
+	// together, which keeps busy evenings under Discord's rate limits.
+	Batch string `yaml:"batch" envconfig:"BATCH"`
+}
+
+// BadgesConfig controls the announcements of unlocked achievements.
+type BadgesConfig struct {
+	// Channel is where unlocked badges are announced, they are only shown
+	// on the kiosk when empty.
+	Channel string `yaml:"channel" envconfig:"CHANNEL"`
+}
+
+// SieConfig controls the SIE4 export used for the bookkeeping.
+type SieConfig struct {
+	// Company is the name of the association written to the file.
+	Company string `yaml:"company" envconfig:"COMPANY"`
+	// OrgNumber is the organisation number, left out of the file if empty.
+	OrgNumber string `yaml:"org_number" envconfig:"ORG_NUMBER"`
+	// FiscalYearStart is the month, 1 to 12, the fiscal year starts in.
+	FiscalYearStart int `yaml:"fiscal_year_start" envconfig:"FISCAL_YEAR_START"`
+	// Series is the verification series the exported verifications use.
+	Series   string      `yaml:"series" envconfig:"SERIES"`
+	Accounts SieAccounts `yaml:"accounts" envconfig:"ACCOUNTS"`
+}
+
+// SieAccounts are the account numbers the exported verifications are
+// booked on.
+type SieAccounts struct {
+	// Cash receives external sales, which are paid on the spot.
+	Cash string `yaml:"cash" envconfig:"CASH"`
+	// Bank receives payments from users.
+	Bank string `yaml:"bank" envconfig:"BANK"`
+	// Members holds what the association owes users for stock, less what
+	// they have bought.
+	Members       string `yaml:"members" envconfig:"MEMBERS"`
+	InternalSales string `yaml:"internal_sales" envconfig:"INTERNAL_SALES"`
+	ExternalSales string `yaml:"external_sales" envconfig:"EXTERNAL_SALES"`
+	Purchases     string `yaml:"purchases" envconfig:"PURCHASES"`
+	WriteOffs     string `yaml:"write_offs" envconfig:"WRITE_OFFS"`
 }
 
 func DefaultConfig() Config {
@@ -27,7 +323,71 @@ func DefaultConfig() Config {
 	return Config{
 		DiscordToken: "",
 		Guild:        "",
+		Guilds:       []GuildConfig{},
 		DbUrl:        escaped,
+		Admins:       []string{},
+		Locale:       "sv",
+		Backup: BackupConfig{
+			Dir:       filepath.Join(xdg.DataHome, "jamkstrecka", "backups"),
+			Retention: 28,
+		},
+		Api: ApiConfig{
+			Enabled: false,
+			Listen:  "127.0.0.1:8420",
+			Tokens:  []ApiToken{},
+		},
+		Metrics: MetricsConfig{
+			Enabled: false,
+			Listen:  "127.0.0.1:9420",
+		},
+		Swish: SwishConfig{
+			Payee:   "",
+			Message: "Streck",
+		},
+		Reminders: RemindersConfig{
+			Threshold: 200,
+			Cooldown:  "168h",
+			Channel:   "",
+		},
+		Sie: SieConfig{
+			Company:         "",
+			OrgNumber:       "",
+			FiscalYearStart: 1,
+			Series:          "A",
+			Accounts: SieAccounts{
+				Cash:          "1910",
+				Bank:          "1930",
+				Members:       "2890",
+				InternalSales: "3010",
+				ExternalSales: "3011",
+				Purchases:     "4010",
+				WriteOffs:     "6351",
+			},
+		},
+		Leaderboard: LeaderboardConfig{
+			Window:     "tonight",
+			Metric:     "units",
+			ProductID:  0,
+			Category:   "",
+			Tonight:    "12h",
+			Change:     "15m",
+			TermStarts: []string{"01-15", "08-15"},
+		},
+		Strecka: StreckaConfig{
+			UndoWindow:     "5m",
+			DisputeChannel: "",
+		},
+		Log: LogConfig{
+			Channel: "",
+			Batch:   "5s",
+		},
+		Badges: BadgesConfig{
+			Channel: "",
+		},
+		Jobs: map[string]string{
+			"backup":    "0 */6 * * *",
+			"reminders": "0 18 * * 1",
+		},
 	}
 }
 
@@ -71,7 +431,7 @@ func (s *ConfigStore) Config() (Config, error) {
 		return DefaultConfig(), nil
 	}
 
-	cfg := Config{}
+	cfg := DefaultConfig()
 	if err := yaml.Unmarshal(buf, &cfg); err != nil {
 		return Config{}, fmt.Errorf("configuration file does not have a valid format: %w", err)
 	}
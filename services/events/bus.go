@@ -0,0 +1,107 @@
+package events
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// subscriberBuffer is the number of events a subscriber may lag behind
+// before new events are dropped for it.
+const subscriberBuffer = 256
+
+// Bus delivers published events to its subscribers. Every subscriber gets
+// its own goroutine and receives events in publish order, so a slow
+// subscriber never blocks the publisher or other subscribers.
+type Bus struct {
+	mu          sync.RWMutex
+	logger      *slog.Logger
+	subscribers map[int]*subscriber
+	nextID      int
+}
+
+type subscriber struct {
+	name   string
+	events chan Event
+	accept func(Event) bool
+}
+
+func NewBus(logger *slog.Logger) *Bus {
+	return &Bus{
+		logger:      logger,
+		subscribers: make(map[int]*subscriber),
+	}
+}
+
+// Publish hands the event to every interested subscriber.
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers {
+		if !sub.accept(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			b.logger.Warn("Subscriber is falling behind, dropping event", "subscriber", sub.name, "event", event.Name())
+		}
+	}
+}
+
+// SubscribeAll calls handler for every published event. The name is only
+// used for logging. The returned function removes the subscription.
+func (b *Bus) SubscribeAll(name string, handler func(Event)) (unsubscribe func()) {
+	return b.subscribe(name, func(Event) bool { return true }, handler)
+}
+
+// Subscribe calls handler for every published event of type T.
+func Subscribe[T Event](b *Bus, name string, handler func(T)) (unsubscribe func()) {
+	return b.subscribe(name, func(event Event) bool {
+		_, ok := event.(T)
+		return ok
+	}, func(event Event) {
+		handler(event.(T))
+	})
+}
+
+func (b *Bus) subscribe(name string, accept func(Event) bool, handler func(Event)) func() {
+	sub := &subscriber{
+		name:   name,
+		events: make(chan Event, subscriberBuffer),
+		accept: accept,
+	}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = sub
+	b.mu.Unlock()
+
+	go func() {
+		for event := range sub.events {
+			b.deliver(sub, handler, event)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, id)
+			b.mu.Unlock()
+			close(sub.events)
+		})
+	}
+}
+
+func (b *Bus) deliver(sub *subscriber, handler func(Event), event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error("Subscriber panicked", "subscriber", sub.name, "event", event.Name(), "panic", r)
+		}
+	}()
+
+	handler(event)
+}
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"gostrecka/models"
+	"time"
+)
+
+// Event is a domain event published on the Bus. Events of the ledger carry
+// the guild whose database they happened in, empty for the default one.
+type Event interface {
+	Name() string
+}
+
+// TransactionCreated is published when a user has streckat a product.
+type TransactionCreated struct {
+	TransactionID int64         `json:"transaction_id"`
+	UserID        string        `json:"user_id"`
+	ProductID     int64         `json:"product_id"`
+	ProductName   string        `json:"product_name"`
+	Quantity      int64         `json:"quantity"`
+	PriceType     string        `json:"price_type"`
+	PricePaid     float64       `json:"price_paid"`
+	Origin        models.Origin `json:"origin"`
+	Guild         string        `json:"guild,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// TransactionReversed is published when a transaction has been undone.
+type TransactionReversed struct {
+	TransactionID int64         `json:"transaction_id"`
+	UserID        string        `json:"user_id"`
+	ProductID     int64         `json:"product_id"`
+	ProductName   string        `json:"product_name"`
+	Quantity      int64         `json:"quantity"`
+	PriceType     string        `json:"price_type"`
+	PricePaid     float64       `json:"price_paid"`
+	Origin        models.Origin `json:"origin"`
+	Guild         string        `json:"guild,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// TransactionDisputed is published when a user disputes a transaction
+// streckat on them by someone else.
+type TransactionDisputed struct {
+	TransactionID int64         `json:"transaction_id"`
+	UserID        string        `json:"user_id"`
+	ProductID     int64         `json:"product_id"`
+	ProductName   string        `json:"product_name"`
+	Quantity      int64         `json:"quantity"`
+	PricePaid     float64       `json:"price_paid"`
+	Origin        models.Origin `json:"origin"`
+	Guild         string        `json:"guild,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// StockAdded is published when a user has added stock for a product.
+type StockAdded struct {
+	StockID     int64         `json:"stock_id"`
+	ProductID   int64         `json:"product_id"`
+	ProductName string        `json:"product_name"`
+	UserID      string        `json:"user_id"`
+	Quantity    int64         `json:"quantity"`
+	TotalStock  int64         `json:"total_stock"`
+	Origin      models.Origin `json:"origin"`
+	Guild       string        `json:"guild,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// PriceChanged is published when a product gets a new price, including
+// the initial price of a new product.
+type PriceChanged struct {
+	ProductID   int64                `json:"product_id"`
+	ProductName string               `json:"product_name"`
+	Before      *models.ProductPrice `json:"before"`
+	After       models.ProductPrice  `json:"after"`
+	Origin      models.Origin        `json:"origin"`
+	Guild       string               `json:"guild,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+}
+
+// UserCreated is published when a user has been registered.
+type UserCreated struct {
+	UserID    string        `json:"user_id"`
+	UserName  string        `json:"user_name"`
+	Origin    models.Origin `json:"origin"`
+	Guild     string        `json:"guild,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// PaymentRecorded is published when a payment from a user, or a write-off
+// of their debt, has been registered.
+type PaymentRecorded struct {
+	PaymentID   int64         `json:"payment_id"`
+	UserID      string        `json:"user_id"`
+	Amount      float64       `json:"amount"`
+	PaymentType string        `json:"payment_type"`
+	Origin      models.Origin `json:"origin"`
+	Guild       string        `json:"guild,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// BadgeUnlocked is published when a user has unlocked an achievement.
+type BadgeUnlocked struct {
+	UserID     string    `json:"user_id"`
+	UserName   string    `json:"user_name"`
+	Badge      string    `json:"badge"`
+	Guild      string    `json:"guild,omitempty"`
+	UnlockedAt time.Time `json:"unlocked_at"`
+}
+
+// DiscordReady is published when the Discord bot has connected.
+type DiscordReady struct {
+	BotName string `json:"name"`
+	IconURL string `json:"icon_url"`
+}
+
+func (TransactionCreated) Name() string  { return "transaction_created" }
+func (TransactionReversed) Name() string { return "transaction_reversed" }
+func (TransactionDisputed) Name() string { return "transaction_disputed" }
+func (StockAdded) Name() string          { return "stock_added" }
+func (PriceChanged) Name() string        { return "price_changed" }
+func (UserCreated) Name() string         { return "user_created" }
+func (PaymentRecorded) Name() string     { return "payment_recorded" }
+func (BadgeUnlocked) Name() string       { return "badge_unlocked" }
+func (DiscordReady) Name() string        { return "discord_ready" }
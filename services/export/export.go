@@ -0,0 +1,168 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"gostrecka/models"
+	"gostrecka/services/reports"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatCsv  = "csv"
+	FormatJson = "json"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+const dateLayout = "2006-01-02 15:04:05"
+
+// Transactions writes transactions as CSV or JSON.
+func Transactions(w io.Writer, format string, transactions []models.TransactionRecord) error {
+	if transactions == nil {
+		transactions = []models.TransactionRecord{}
+	}
+
+	return write(w, format, transactions,
+		[]string{"id", "transaction_date", "user_id", "user_name", "product_id", "product_name", "quantity", "price_type", "price_paid", "total"},
+		func(yield func([]string) error) error {
+			for _, t := range transactions {
+				err := yield([]string{
+					strconv.FormatInt(t.ID, 10),
+					formatDate(t.TransactionDate),
+					t.UserID,
+					t.UserName,
+					strconv.FormatInt(t.ProductID, 10),
+					t.ProductName,
+					strconv.FormatInt(t.Quantity, 10),
+					t.PriceType,
+					formatAmount(t.PricePaid),
+					formatAmount(t.PricePaid * float64(t.Quantity)),
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// Users writes users and their balances as CSV or JSON.
+func Users(w io.Writer, format string, users []models.UserWithBalance) error {
+	if users == nil {
+		users = []models.UserWithBalance{}
+	}
+
+	return write(w, format, users,
+		[]string{"id", "name", "total_credits_earned", "total_payments_made", "total_debt_incurred", "remaining_credits", "debt_incurred"},
+		func(yield func([]string) error) error {
+			for _, u := range users {
+				err := yield([]string{
+					u.User.ID,
+					u.User.Name,
+					formatAmount(u.Balance.TotalCreditsEarned),
+					formatAmount(u.Balance.TotalPaymentsMade),
+					formatAmount(u.Balance.TotalDebtIncurred),
+					formatAmount(u.Balance.RemainingCredits),
+					formatAmount(u.Balance.DebtIncurred),
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// Products writes every price period of every product as CSV or JSON.
+func Products(w io.Writer, format string, prices []models.ProductWithPrice) error {
+	if prices == nil {
+		prices = []models.ProductWithPrice{}
+	}
+
+	return write(w, format, prices,
+		[]string{"product_id", "name", "category", "total_stock", "purchase_price", "internal_price", "external_price", "start_date", "end_date"},
+		func(yield func([]string) error) error {
+			for _, p := range prices {
+				err := yield([]string{
+					strconv.FormatInt(p.Product.ID, 10),
+					p.Product.Name,
+					p.Product.Category,
+					strconv.Itoa(p.Product.TotalStock),
+					formatAmount(p.Price.PurchasePrice),
+					formatAmount(p.Price.InternalPrice),
+					formatAmount(p.Price.ExternalPrice),
+					formatDate(p.Price.StartDate),
+					formatDate(p.Price.EndDate),
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// Report writes a sales report as CSV, ending with the totals, or as JSON.
+func Report(w io.Writer, format string, report reports.Report) error {
+	return write(w, format, report,
+		[]string{"key", "label", "quantity", "revenue", "cost", "margin", "margin_percent"},
+		func(yield func([]string) error) error {
+			for _, l := range append(report.Lines, report.Total) {
+				err := yield([]string{
+					l.Key,
+					l.Label,
+					strconv.FormatInt(l.Quantity, 10),
+					formatAmount(l.Revenue),
+					formatAmount(l.Cost),
+					formatAmount(l.Margin),
+					formatAmount(l.MarginPercent),
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// write encodes data as JSON, or as CSV with header and the records
+// produced by rows.
+func write(w io.Writer, format string, data any, header []string, rows func(yield func([]string) error) error) error {
+	switch format {
+	case FormatJson:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	case FormatCsv:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		if err := rows(writer.Write); err != nil {
+			return err
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(dateLayout)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
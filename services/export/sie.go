@@ -0,0 +1,160 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"gostrecka/models"
+	"gostrecka/services/env"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+const sieDateLayout = "20060102"
+
+// sieKind describes how one kind of ledger entry is booked. Amounts are
+// debited to Debit and credited to Credit.
+type sieKind struct {
+	kind   string
+	text   string
+	debit  func(env.SieAccounts) string
+	credit func(env.SieAccounts) string
+}
+
+// sieKinds lists the verifications in the order they are written for each
+// month.
+var sieKinds = []sieKind{
+	{
+		kind:   models.LedgerInternalSale,
+		text:   "Försäljning internt",
+		debit:  func(a env.SieAccounts) string { return a.Members },
+		credit: func(a env.SieAccounts) string { return a.InternalSales },
+	},
+	{
+		kind:   models.LedgerExternalSale,
+		text:   "Försäljning externt",
+		debit:  func(a env.SieAccounts) string { return a.Cash },
+		credit: func(a env.SieAccounts) string { return a.ExternalSales },
+	},
+	{
+		kind:   models.LedgerStock,
+		text:   "Inköp krediterade medlemmar",
+		debit:  func(a env.SieAccounts) string { return a.Purchases },
+		credit: func(a env.SieAccounts) string { return a.Members },
+	},
+	{
+		kind:   models.LedgerPayment,
+		text:   "Inbetalningar från medlemmar",
+		debit:  func(a env.SieAccounts) string { return a.Bank },
+		credit: func(a env.SieAccounts) string { return a.Members },
+	},
+	{
+		kind:   models.LedgerWriteOff,
+		text:   "Avskrivna skulder",
+		debit:  func(a env.SieAccounts) string { return a.WriteOffs },
+		credit: func(a env.SieAccounts) string { return a.Members },
+	},
+}
+
+// Sie writes an SIE4 file for the period [from, to) with one verification
+// per month for each kind of ledger entry, dated the last day with entries
+// of that kind.
+func Sie(w io.Writer, config env.SieConfig, from time.Time, to time.Time, entries []models.LedgerEntry) error {
+	// SIE files are encoded as IBM PC 8-bit extended ASCII, codepage 437.
+	out := bufio.NewWriter(encoding.ReplaceUnsupported(charmap.CodePage437.NewEncoder()).Writer(w))
+
+	fiscalStart := fiscalYearStart(from, config.FiscalYearStart)
+
+	fmt.Fprintln(out, "#FLAGGA 0")
+	fmt.Fprintln(out, "#FORMAT PC8")
+	fmt.Fprintln(out, "#SIETYP 4")
+	fmt.Fprintln(out, `#PROGRAM "jamkstrecka" "0.0.1"`)
+	fmt.Fprintf(out, "#GEN %s\n", time.Now().Format(sieDateLayout))
+	fmt.Fprintf(out, "#FNAMN %s\n", sieQuote(config.Company))
+	if config.OrgNumber != "" {
+		fmt.Fprintf(out, "#ORGNR %s\n", config.OrgNumber)
+	}
+	fmt.Fprintf(out, "#RAR 0 %s %s\n", fiscalStart.Format(sieDateLayout), fiscalStart.AddDate(1, 0, -1).Format(sieDateLayout))
+	fmt.Fprintf(out, "#PERIOD %s %s\n", from.Format(sieDateLayout), to.AddDate(0, 0, -1).Format(sieDateLayout))
+
+	accounts := config.Accounts
+	for _, account := range []struct{ number, name string }{
+		{accounts.Cash, "Kassa"},
+		{accounts.Bank, "Bank"},
+		{accounts.Members, "Medlemmarnas konton"},
+		{accounts.InternalSales, "Försäljning internt"},
+		{accounts.ExternalSales, "Försäljning externt"},
+		{accounts.Purchases, "Inköp varor"},
+		{accounts.WriteOffs, "Avskrivna fordringar"},
+	} {
+		fmt.Fprintf(out, "#KONTO %s %s\n", account.number, sieQuote(account.name))
+	}
+
+	type total struct {
+		date   time.Time
+		amount float64
+	}
+
+	var months []time.Time
+	totals := make(map[time.Time]map[string]*total)
+	for _, entry := range entries {
+		month := time.Date(entry.Date.Year(), entry.Date.Month(), 1, 0, 0, 0, 0, time.Local)
+		if totals[month] == nil {
+			totals[month] = make(map[string]*total)
+			months = append(months, month)
+		}
+
+		t := totals[month][entry.Kind]
+		if t == nil {
+			t = &total{}
+			totals[month][entry.Kind] = t
+		}
+
+		t.amount += entry.Amount
+		if entry.Date.After(t.date) {
+			t.date = entry.Date
+		}
+	}
+
+	number := 1
+	for _, month := range months {
+		for _, kind := range sieKinds {
+			t := totals[month][kind.kind]
+			if t == nil || fmt.Sprintf("%.2f", t.amount) == "0.00" {
+				continue
+			}
+
+			text := fmt.Sprintf("%s %s", kind.text, month.Format("2006-01"))
+			fmt.Fprintf(out, "#VER %s %d %s %s\n", sieQuote(config.Series), number, t.date.Format(sieDateLayout), sieQuote(text))
+			fmt.Fprintln(out, "{")
+			fmt.Fprintf(out, "   #TRANS %s {} %.2f\n", kind.debit(accounts), t.amount)
+			fmt.Fprintf(out, "   #TRANS %s {} %.2f\n", kind.credit(accounts), -t.amount)
+			fmt.Fprintln(out, "}")
+
+			number++
+		}
+	}
+
+	return out.Flush()
+}
+
+// fiscalYearStart returns the first day of the fiscal year containing t.
+func fiscalYearStart(t time.Time, month int) time.Time {
+	if month < 1 || month > 12 {
+		month = 1
+	}
+
+	year := t.Year()
+	if int(t.Month()) < month {
+		year--
+	}
+
+	return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+}
+
+func sieQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
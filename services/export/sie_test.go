@@ -0,0 +1,139 @@
+package export
+
+import (
+	"bytes"
+	"gostrecka/models"
+	"gostrecka/services/env"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func sieDate(month time.Month, day int) time.Time {
+	return time.Date(2026, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// sieLines returns the verification lines of an SIE export, decoded from
+// codepage 437.
+func sieLines(t *testing.T, config env.SieConfig, entries []models.LedgerEntry) []string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := Sie(&buf, config, sieDate(time.January, 1), sieDate(time.April, 1), entries); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := charmap.CodePage437.NewDecoder().Bytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	inVer := false
+	for _, line := range strings.Split(strings.TrimSpace(string(decoded)), "\n") {
+		if strings.HasPrefix(line, "#VER") {
+			inVer = true
+		}
+		if inVer {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+
+	return lines
+}
+
+func TestSie(t *testing.T) {
+	config := env.DefaultConfig().Sie
+
+	lines := sieLines(t, config, []models.LedgerEntry{
+		{Date: sieDate(time.January, 3), Kind: models.LedgerInternalSale, Amount: 100},
+		{Date: sieDate(time.January, 20), Kind: models.LedgerInternalSale, Amount: 50.5},
+		{Date: sieDate(time.January, 10), Kind: models.LedgerPayment, Amount: 200},
+		{Date: sieDate(time.February, 2), Kind: models.LedgerStock, Amount: 80},
+		{Date: sieDate(time.February, 5), Kind: models.LedgerWriteOff, Amount: 15},
+	})
+
+	want := []string{
+		`#VER "A" 1 20260120 "Försäljning internt 2026-01"`,
+		"{",
+		"#TRANS 2890 {} 150.50",
+		"#TRANS 3010 {} -150.50",
+		"}",
+		`#VER "A" 2 20260110 "Inbetalningar från medlemmar 2026-01"`,
+		"{",
+		"#TRANS 1930 {} 200.00",
+		"#TRANS 2890 {} -200.00",
+		"}",
+		`#VER "A" 3 20260202 "Inköp krediterade medlemmar 2026-02"`,
+		"{",
+		"#TRANS 4010 {} 80.00",
+		"#TRANS 2890 {} -80.00",
+		"}",
+		`#VER "A" 4 20260205 "Avskrivna skulder 2026-02"`,
+		"{",
+		"#TRANS 6351 {} 15.00",
+		"#TRANS 2890 {} -15.00",
+		"}",
+	}
+
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Sie() verifications =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSieSkipsZeroSums(t *testing.T) {
+	lines := sieLines(t, env.DefaultConfig().Sie, []models.LedgerEntry{
+		{Date: sieDate(time.January, 3), Kind: models.LedgerExternalSale, Amount: 30},
+		{Date: sieDate(time.January, 4), Kind: models.LedgerExternalSale, Amount: -30},
+		{Date: sieDate(time.January, 5), Kind: models.LedgerPayment, Amount: 0.001},
+		{Date: sieDate(time.March, 1), Kind: models.LedgerInternalSale, Amount: 10},
+	})
+
+	if len(lines) == 0 || lines[0] != `#VER "A" 1 20260301 "Försäljning internt 2026-03"` || len(lines) != 5 {
+		t.Errorf("Sie() verifications = %q, want only the March sale numbered 1", lines)
+	}
+}
+
+func TestSieEncoding(t *testing.T) {
+	config := env.DefaultConfig().Sie
+	config.Company = `Jämkat "JK"`
+	config.OrgNumber = "802000-0000"
+
+	var buf bytes.Buffer
+	if err := Sie(&buf, config, sieDate(time.January, 1), sieDate(time.February, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// ä is 0x84 in codepage 437, UTF-8 would be two bytes.
+	if !bytes.Contains(buf.Bytes(), []byte("#FNAMN \"J\x84mkat \\\"JK\\\"\"\n")) {
+		t.Errorf("company name not quoted and encoded in codepage 437:\n%q", buf.String())
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("#ORGNR 802000-0000\n")) {
+		t.Errorf("organisation number missing:\n%q", buf.String())
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("#PERIOD 20260101 20260131\n")) {
+		t.Errorf("period is not the inclusive last day:\n%q", buf.String())
+	}
+}
+
+func TestFiscalYearStart(t *testing.T) {
+	tests := []struct {
+		t     time.Time
+		month int
+		want  time.Time
+	}{
+		{sieDate(time.March, 15), 1, sieDate(time.January, 1)},
+		{sieDate(time.March, 15), 7, time.Date(2025, time.July, 1, 0, 0, 0, 0, time.Local)},
+		{sieDate(time.July, 1), 7, sieDate(time.July, 1)},
+		{sieDate(time.March, 15), 0, sieDate(time.January, 1)},
+		{sieDate(time.March, 15), 13, sieDate(time.January, 1)},
+	}
+
+	for _, tt := range tests {
+		if got := fiscalYearStart(tt.t, tt.month); !got.Equal(tt.want) {
+			t.Errorf("fiscalYearStart(%v, %d) = %v, want %v", tt.t, tt.month, got, tt.want)
+		}
+	}
+}
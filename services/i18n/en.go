@@ -0,0 +1,428 @@
+package i18n
+
+// english also holds the command names and descriptions shown to Discord
+// clients in English, keyed by "command." and the path of the command or
+// option. Choices add "=" and their value, names of context menu commands
+// are keyed by "command_name." and the command name.
+var english = map[string]string{
+	"locale.name": "English",
+
+	"error.title":             "Error",
+	"error.internal":          "Internal error",
+	"error.forbidden":         "You are not allowed to do this",
+	"error.invalid_date":      "Invalid date, use the format YYYY-MM-DD",
+	"error.product_not_found": "Product not found",
+	"error.user_not_found":    "User not found",
+	"error.not_registered":    "%s is not registered, register with /user create",
+	"error.self_unregistered": "You are not registered\nRegister with /user create",
+	"error.default_guild":     "This command only works in the main server",
+
+	"help.title":          "Help",
+	"help.details":        "Use `/help <command>` to read more about a command.",
+	"help.admin_commands": "Admin commands",
+	"help.user_menu":      "Right-click a user",
+	"help.message_menu":   "Right-click a message",
+	"help.host":           "Running on %s %s (%s)",
+	"help.usage":          "Usage",
+	"help.notation":       "<> is required, [] is optional",
+	"help.admin_only":     "Admins only",
+	"help.dm_capable":     "Works in direct messages",
+	"help.unknown":        "There is no command /%s",
+
+	"strecka.receipt":           "Tallying %d × %s (%.02f)",
+	"strecka.for_self":          "%s for you",
+	"strecka.for_user":          "%s for %s",
+	"strecka.failed":            "Could not tally",
+	"strecka.undo":              "Undo",
+	"strecka.dispute":           "Dispute",
+	"strecka.undone_by":         "Undone by %s",
+	"strecka.disputed_by":       "Disputed by %s",
+	"strecka.undo_forbidden":    "Only whoever tallied can undo",
+	"strecka.undo_too_late":     "It is too late to undo, contact an admin",
+	"strecka.undo_failed":       "Could not undo",
+	"strecka.repeat_forbidden":  "Only whoever tallied can tally again",
+	"strecka.dispute_forbidden": "Only the user who was charged can dispute",
+	"strecka.dispute_gone":      "The tally no longer exists",
+	"strecka.dispute_failed":    "Could not dispute the tally",
+	"strecka.dispute_title":     "Disputed tally",
+	"strecka.dispute_post":      "%s disputes a tally made by %s",
+	"strecka.dispute_receipt":   "Tally",
+	"strecka.dispute_reverse":   "Reverse with `/reverse transaction:%d`",
+
+	"reverse.title":     "Tally reversed",
+	"reverse.done":      "Reversed %d × %s (%.02f kr) for %s",
+	"reverse.not_found": "Transaction %d does not exist or is already reversed",
+	"reverse.failed":    "Could not reverse the tally",
+
+	"picker.bot":          "Bots cannot be tallied for",
+	"picker.failed":       "Could not get the products",
+	"picker.prompt":       "Pick a product to tally for %s",
+	"picker.query":        "%s (search: %s)",
+	"picker.truncated":    "Showing %d of %d products, search to find more",
+	"picker.empty":        "There are no products",
+	"picker.no_match":     "No products found for %q",
+	"picker.option":       "%.02f kr, %d in stock",
+	"picker.placeholder":  "Product",
+	"picker.search":       "Search",
+	"picker.search_title": "Search products",
+	"picker.search_label": "Product name",
+	"picker.not_yours":    "This is not your product picker",
+	"picker.done":         "Tallied for %s",
+	"picker.no_message":   "Message not found",
+
+	"balance.title":        "Balance",
+	"balance.description":  "Balance for %s",
+	"balance.current":      "Current",
+	"balance.credit":       "%.02f kr in credit",
+	"balance.debt":         "%.02f kr in debt",
+	"balance.total_debt":   "Total debt",
+	"balance.total_credit": "Total credit",
+	"balance.swish_title":  "Pay with Swish",
+	"balance.swish":        "Scan the code or Swish %.02f kr to %s with the message `%s`",
+
+	"reminder.title":            "Debt reminder",
+	"reminder.description":      "Hi %s! You owe **%.02f kr** on the tally list.",
+	"reminder.footer":           "You can turn the reminders off with /settings",
+	"reminder.swish":            "Scan the code or Swish to %s with the message `%s`",
+	"reminder.pay_title":        "Pay",
+	"reminder.pay":              "Contact the treasurer to pay your debt",
+	"reminder.summary_title":    "Debt reminders",
+	"reminder.summary":          "%d reminders sent, %d failed",
+	"reminder.summary_reminded": "Reminded",
+	"reminder.summary_failed":   "Could not be reached",
+	"reminder.summary_line":     "<@%s> %.02f kr",
+	"reminder.summary_error":    "Stopped",
+
+	"history.title":    "History for %s",
+	"history.failed":   "Could not get the history",
+	"history.empty":    "No tallies in the period",
+	"history.external": "%s (external price)",
+	"history.footer":   "Page %d of %d, %d tallies in total",
+	"history.previous": "Previous",
+	"history.next":     "Next",
+
+	"settings.title":         "Settings",
+	"settings.get_failed":    "Could not get the settings",
+	"settings.save_failed":   "Could not save the settings",
+	"settings.reminders":     "Debt reminders",
+	"settings.on":            "On",
+	"settings.off":           "Off",
+	"settings.language":      "Language",
+	"settings.language_auto": "Automatic (%s)",
+
+	"log.strecka_title":   "Tally",
+	"log.strecka":         "%s tallied %d × %s (%.02f kr) for %s",
+	"log.reversal_title":  "Undone tally",
+	"log.reversal":        "%s undid %d × %s (%.02f kr) for %s",
+	"log.stock_title":     "Stock",
+	"log.stock":           "%s added %d × %s bought by %s, %d in stock",
+	"log.product_title":   "New product",
+	"log.product":         "%s created %s at %s (purchase/internal/external)",
+	"log.price_title":     "Price change",
+	"log.price":           "%s changed the price of %s from %s to %s (purchase/internal/external)",
+	"log.prices":          "%.02f/%.02f/%.02f kr",
+	"log.payment_title":   "Payment",
+	"log.payment":         "%s recorded a payment of %.02f kr from %s",
+	"log.write_off_title": "Write-off",
+	"log.write_off":       "%s wrote off %.02f kr of the debt of %s",
+	"log.unknown":         "Unknown",
+
+	"badge.first_strecka":             "First tally",
+	"badge.first_strecka_description": "Tallied for the first time",
+	"badge.hundred_cans":              "A hundred cans",
+	"badge.hundred_cans_description":  "Has tallied 100 items",
+	"badge.every_product":             "Taster",
+	"badge.every_product_description": "Has tallied every product",
+	"badge.night_owl":                 "Night owl",
+	"badge.night_owl_description":     "Tallied between midnight and five",
+	"badge.top_restocker":             "Big shopper",
+	"badge.top_restocker_description": "Has added the most stock of everyone",
+	"badge.announcement":              "New badge",
+	"badge.unlocked":                  "%s has unlocked %s **%s**: %s",
+	"badge.kiosk":                     "%s unlocked %s %s!",
+
+	"badges.title":  "Badges of %s",
+	"badges.failed": "Could not get the badges",
+	"badges.locked": "Not unlocked yet",
+	"badges.footer": "%d of %d badges",
+
+	"product.title":                   "Product",
+	"product.purchase_price":          "Purchase price",
+	"product.internal_price":          "Internal price",
+	"product.external_price":          "External price",
+	"product.stock":                   "Stock",
+	"product.stock_count":             "%d",
+	"product.category":                "Category",
+	"product.uncategorised":           "Uncategorised",
+	"product.category_title":          "Category updated",
+	"product.category_set":            "%s is now reported under %s",
+	"product.category_failed":         "Could not set the category",
+	"product.stock_updated":           "Stock updated for %s",
+	"product.stock_failed":            "Could not add the stock",
+	"product.price_failed":            "Could not update the price",
+	"product.user_failed":             "Could not get the user",
+	"product.buyer":                   "Buyer",
+	"product.buyer_credit":            "%s got %.02f kr to spend 😋",
+	"product.name_missing":            "The name is missing",
+	"product.name_too_long":           "The name can be at most %d characters",
+	"product.name_check_failed":       "Could not check whether the name is taken",
+	"product.name_taken":              "There is already a product called %s",
+	"product.negative_price":          "Prices cannot be negative",
+	"product.internal_below_purchase": "The internal price (%.02f kr) is lower than the purchase price (%.02f kr)",
+	"product.external_below_internal": "The external price (%.02f kr) is lower than the internal price (%.02f kr)",
+	"product.negative_stock":          "The stock cannot be negative",
+	"product.invalid_upc":             "The barcode must be 8 to 14 digits",
+	"product.upc_taken":               "The barcode is already in use",
+
+	"form.new":                "New product",
+	"form.edit":               "Edit %s",
+	"form.intro":              "Fill in the form, nothing is saved until you confirm",
+	"form.open":               "Open form",
+	"form.change":             "Change",
+	"form.save":               "Save",
+	"form.cancel":             "Cancel",
+	"form.not_yours":          "This form belongs to someone else",
+	"form.name":               "Name",
+	"form.prices":             "Prices: purchase / internal / external",
+	"form.stock":              "Stock to add",
+	"form.upc":                "Barcode",
+	"form.upc_placeholder":    "Created if left empty",
+	"form.upc_auto":           "Created automatically",
+	"form.problems":           "The product cannot be saved:\n- %s",
+	"form.confirm":            "Is this correct?",
+	"form.invalid_prices":     "Write the prices as purchase / internal / external, for example 10 / 12.5 / 15",
+	"form.invalid_stock":      "The stock must be a whole number",
+	"form.stock_unregistered": "You must be registered to add stock, register with /user create",
+	"form.created":            "Product created: %s",
+	"form.add_stock":          "Time to add some stock!",
+	"form.updated":            "Product updated: %s",
+	"form.save_failed":        "The product could not be saved, try again",
+	"form.cancelled":          "Cancelled, nothing was saved",
+
+	"leaderboard.title_tonight": "Leaderboard tonight",
+	"leaderboard.title_week":    "Leaderboard this week",
+	"leaderboard.title_term":    "Leaderboard this term",
+	"leaderboard.title_all":     "Leaderboard of all time",
+	"leaderboard.unknown":       "Unknown period or metric",
+	"leaderboard.failed":        "Could not get the leaderboard",
+	"leaderboard.render_failed": "Could not draw the leaderboard",
+	"leaderboard.empty":         "Nobody has tallied yet",
+	"leaderboard.units":         "%.0f",
+	"leaderboard.spend":         "%.0f kr",
+	"leaderboard.new":           "new",
+
+	"report.title":  "Report %s to %s",
+	"report.failed": "Could not create the report",
+	"report.more":   "%d more rows are in the attached file",
+	"report.empty":  "Nothing was sold in the period",
+	"report.line":   "%d sold, revenue %.02f kr, cost %.02f kr, margin %.02f kr (%.0f%%)",
+	"report.total":  "Total",
+
+	"audit.title":  "Audit log",
+	"audit.failed": "Could not get the audit log",
+	"audit.empty":  "No events found",
+	"audit.entry":  "`#%d` %s **%s** via %s",
+	"audit.by":     "by %s",
+	"audit.for":    "for %s",
+
+	"export.failed":              "Could not create the export",
+	"export.transactions_failed": "Could not get the tallies",
+	"export.ledger_failed":       "Could not get the ledger",
+	"export.users_failed":        "Could not get the users",
+	"export.products_failed":     "Could not get the products",
+	"export.sie":                 "SIE file for %s to %s",
+
+	"import.too_large":          "The file can be at most %d kB",
+	"import.download_failed":    "Could not download the file",
+	"import.failed":             "The import failed, no products were created: %s",
+	"import.dry_run_title":      "Dry run",
+	"import.done_title":         "Import done",
+	"import.applied":            "%d of %d products were created",
+	"import.rejected":           "Nothing was imported, %d errors found",
+	"import.dry_run":            "Dry run, %d products would be created",
+	"import.line":               "Line %d: %s",
+	"import.row":                "Line %d: %s (%.02f / %.02f / %.02f kr), %d in stock",
+	"import.empty_file":         "The file is empty",
+	"import.missing_column":     "The column %q is missing",
+	"import.name_missing":       "name is missing",
+	"import.invalid_price":      "%s must be a number that is not negative",
+	"import.invalid_stock":      "stock must be a whole number that is not negative",
+	"import.duplicate":          "%s is already on line %d",
+	"import.search_failed":      "Could not search for the product",
+	"import.exists":             "%s already exists as product %d",
+	"import.stock_user_missing": "Stock needs a user to credit",
+	"import.stock_user_unknown": "The user %s does not exist",
+
+	"statement.invalid_month":     "Invalid month, use the format YYYY-MM",
+	"statement.get_failed":        "Could not get the statement",
+	"statement.create_failed":     "Could not create the statement",
+	"statement.send_failed":       "Could not send the statement, do you allow direct messages?",
+	"statement.dm":                "Statement for %s",
+	"statement.sent":              "The statement for %s has been sent as a direct message",
+	"statement.get_all_failed":    "Could not get the statements",
+	"statement.create_all_failed": "Could not create the statements",
+	"statement.none":              "No statements to create for %s",
+	"statement.all":               "%d statements for %s",
+	"statement.pdf_title":         "Statement",
+	"statement.pdf_period":        "Period: %s – %s",
+	"statement.pdf_date":          "Date",
+	"statement.pdf_kind":          "Event",
+	"statement.pdf_product":       "Product",
+	"statement.pdf_quantity":      "Qty",
+	"statement.pdf_unit_price":    "Unit price",
+	"statement.pdf_amount":        "Amount",
+	"statement.pdf_opening":       "Opening balance",
+	"statement.pdf_closing":       "Closing balance",
+	"statement.pdf_empty":         "Nothing happened during the period",
+	"statement.pdf_note":          "A positive balance is credit, a negative balance is debt.",
+	"statement.pdf_money":         "%.02f kr",
+	"statement.kind_strecka":      "Tally",
+	"statement.kind_stock":        "Purchase",
+	"statement.kind_payment":      "Payment",
+
+	"jobs.title":           "Jobs",
+	"jobs.failed":          "Could not get the jobs",
+	"jobs.job":             "%s\nSchedule: %s\nLast run: %s",
+	"jobs.manual":          "Manual only",
+	"jobs.next":            "`%s`, next <t:%d:R>",
+	"jobs.never":           "Never",
+	"jobs.running":         "Running now",
+	"jobs.ok":              "<t:%d:f>, succeeded",
+	"jobs.failed_run":      "<t:%d:f>, failed: %s",
+	"jobs.aborted":         "<t:%d:f>, aborted",
+	"jobs.unknown":         "There is no job called `%s`",
+	"jobs.already_running": "The job `%s` is already running",
+	"jobs.run_failed":      "The job `%s` failed: %s",
+	"jobs.done":            "The job `%s` ran",
+
+	"job.backup":    "Backs up the databases",
+	"job.reminders": "Reminds users of their debts",
+
+	"backup.failed":      "Could not get a backup: %s",
+	"backup.read_failed": "Could not read the backup",
+	"backup.file":        "Backup `%s`",
+
+	"payment.title_payment":   "Payment recorded",
+	"payment.title_write_off": "Debt written off",
+	"payment.done_payment":    "Recorded %.02f kr from %s, the debt is now %.02f kr",
+	"payment.done_write_off":  "Wrote off %.02f kr of the debt of %s, the debt is now %.02f kr",
+	"payment.failed":          "Could not record the payment",
+
+	"user.exists":        "User already exists",
+	"user.create_failed": "Could not create the user",
+	"user.created":       "User %s created",
+
+	"command_name.Strecka åt…":           "Tally for…",
+	"command_name.Strecka åt avsändaren": "Tally for author",
+	"command_name.Visa saldo":            "Show balance",
+	"command_name.Visa historik":         "Show history",
+
+	"command.help":                             "Shows the commands",
+	"command.help.command":                     "Command to read more about",
+	"command.user":                             "Commands for users",
+	"command.user.create":                      "Creates an account for you",
+	"command.user.create.user":                 "User to create an account for",
+	"command.strecka":                          "Tallies a product for you (or someone else)",
+	"command.strecka.product":                  "Product to tally",
+	"command.strecka.user":                     "User to tally for",
+	"command.strecka.amount":                   "Number to tally",
+	"command.product":                          "Commands for products",
+	"command.product.create":                   "Creates a product",
+	"command.product.edit":                     "Changes the name, category, prices or barcode of a product",
+	"command.product.edit.product":             "Product to change",
+	"command.product.stock":                    "Adds stock",
+	"command.product.stock.product":            "Product to add stock for",
+	"command.product.stock.amount":             "Number to add",
+	"command.product.stock.user":               "User who bought the stock",
+	"command.product.stock.purchase_price":     "Update the purchase price?",
+	"command.product.stock.internal_price":     "Update the internal price?",
+	"command.product.stock.external_price":     "Update the external price?",
+	"command.product.info":                     "Shows information about a product",
+	"command.product.info.product":             "Product to show",
+	"command.product.category":                 "Sets the category a product is reported under",
+	"command.product.category.product":         "Product to set the category of",
+	"command.product.category.category":        "The category, leave out to remove the category",
+	"command.balance":                          "Shows your balance",
+	"command.balance.user":                     "User to show the balance of",
+	"command.print":                            "Creates PDFs with barcodes to scan",
+	"command.backup":                           "Uploads the latest backup of the database",
+	"command.backup.new":                       "Take a new backup first",
+	"command.audit":                            "Shows the audit log of all changes",
+	"command.audit.user":                       "User who was affected",
+	"command.audit.actor":                      "User who made the change",
+	"command.audit.origin":                     "Where the change was made",
+	"command.audit.action":                     "Kind of change",
+	"command.audit.action=transaction.create":  "Tally",
+	"command.audit.action=transaction.reverse": "Undone tally",
+	"command.audit.action=transaction.dispute": "Disputed tally",
+	"command.audit.action=stock.add":           "Stock",
+	"command.audit.action=payment.record":      "Payment",
+	"command.audit.action=product.price":       "Price change",
+	"command.audit.action=product.create":      "New product",
+	"command.audit.action=product.category":    "Product category",
+	"command.audit.action=product.rename":      "Product name",
+	"command.audit.action=product.upc":         "Barcode",
+	"command.audit.action=user.create":         "New user",
+	"command.audit.action=user.settings":       "Settings",
+	"command.audit.limit":                      "Number of rows to show (at most 25)",
+	"command.reverse":                          "Reverses a tally, such as one that was disputed",
+	"command.reverse.transaction":              "Transaction to reverse",
+	"command.payment":                          "Records a payment or write-off of a user's debt",
+	"command.payment.user":                     "User who paid",
+	"command.payment.amount":                   "Amount in kronor",
+	"command.payment.type":                     "Kind of payment, a payment by default",
+	"command.payment.type=payment":             "Payment",
+	"command.payment.type=write_off":           "Write-off",
+	"command.statement":                        "Sends your statement for a month as a PDF",
+	"command.statement.month":                  "Month as YYYY-MM, the current month by default",
+	"command.statement.all":                    "Create statements for all users (admin only)",
+	"command.settings":                         "Shows or changes your settings",
+	"command.settings.reminders":               "Receive debt reminders as direct messages",
+	"command.settings.language":                "Language of the bot's responses",
+	"command.settings.language=auto":           "Automatic",
+	"command.jobs":                             "Shows and starts scheduled jobs",
+	"command.jobs.list":                        "Shows all jobs and when they run",
+	"command.jobs.run":                         "Runs a job now",
+	"command.jobs.run.job":                     "Job to run",
+	"command.export":                           "Exports data as CSV, JSON or SIE",
+	"command.export.transactions":              "Exports all tallies in a period",
+	"command.export.transactions.from":         "First day as YYYY-MM-DD, the start of the month by default",
+	"command.export.transactions.to":           "Last day as YYYY-MM-DD, today by default",
+	"command.export.transactions.format":       "File format, CSV by default",
+	"command.export.users":                     "Exports all users with balances",
+	"command.export.users.format":              "File format, CSV by default",
+	"command.export.products":                  "Exports all products with price history",
+	"command.export.products.format":           "File format, CSV by default",
+	"command.export.sie":                       "Exports the bookkeeping for a period as an SIE4 file",
+	"command.export.sie.from":                  "First day as YYYY-MM-DD, the start of the month by default",
+	"command.export.sie.to":                    "Last day as YYYY-MM-DD, today by default",
+	"command.import":                           "Creates products from a CSV file",
+	"command.import.file":                      "CSV with the columns name, purchase_price, internal_price, external_price and stock",
+	"command.import.apply":                     "Create the products, otherwise only a dry run is made",
+	"command.import.user":                      "User credited for the stock, yourself by default",
+	"command.report":                           "Shows revenue, cost of goods and margin for a period",
+	"command.report.group":                     "What the report is split by, product by default",
+	"command.report.group=product":             "Product",
+	"command.report.group=category":            "Category",
+	"command.report.group=day":                 "Day",
+	"command.report.group=month":               "Month",
+	"command.report.from":                      "First day as YYYY-MM-DD, the start of the month by default",
+	"command.report.to":                        "Last day as YYYY-MM-DD, today by default",
+	"command.leaderboard":                      "Shows the leaderboard and how it came about",
+	"command.leaderboard.period":               "Period of the leaderboard, the kiosk's by default",
+	"command.leaderboard.period=tonight":       "Tonight",
+	"command.leaderboard.period=week":          "This week",
+	"command.leaderboard.period=term":          "This term",
+	"command.leaderboard.period=all":           "All time",
+	"command.leaderboard.metric":               "What the leaderboard counts, the kiosk's by default",
+	"command.leaderboard.metric=units":         "Units",
+	"command.leaderboard.metric=spend":         "Spend",
+	"command.leaderboard.product":              "Count only one product",
+	"command.leaderboard.category":             "Count only one product category",
+	"command.history":                          "Shows what you have tallied",
+	"command.history.user":                     "Show another user's history (admin only)",
+	"command.history.from":                     "First day as YYYY-MM-DD",
+	"command.history.to":                       "Last day as YYYY-MM-DD",
+	"command.badges":                           "Shows the badges a user has unlocked",
+	"command.badges.user":                      "User to show the badges of, yourself by default",
+}
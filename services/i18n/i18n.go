@@ -0,0 +1,82 @@
+// Package i18n holds the messages shown to users of the bot and the kiosk
+// in every language they can be shown in.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Locale is a language messages can be shown in.
+type Locale string
+
+const (
+	Swedish Locale = "sv"
+	English Locale = "en"
+)
+
+// Default is the language used when neither the user nor their client has
+// picked one the catalogue has.
+const Default = Swedish
+
+// Locales are the languages the catalogue has, in the order they are
+// offered to users.
+var Locales = []Locale{Swedish, English}
+
+var catalogue = map[Locale]map[string]string{
+	Swedish: swedish,
+	English: english,
+}
+
+// Parse reads a language tag such as "sv", "en" or "en-GB", with the
+// region ignored. ok is false for languages the catalogue does not have.
+func Parse(tag string) (locale Locale, ok bool) {
+	language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	locale = Locale(language)
+	_, ok = catalogue[locale]
+
+	return
+}
+
+// Resolve returns the first of tags the catalogue has a language for, or
+// the default language when it has none of them. Empty tags are skipped,
+// so preferences that have not been set can be passed as they are.
+func Resolve(tags ...string) Locale {
+	for _, tag := range tags {
+		if locale, ok := Parse(tag); ok {
+			return locale
+		}
+	}
+
+	return Default
+}
+
+// Name returns the name of the language in the language itself.
+func (l Locale) Name() string {
+	return T(l, "locale.name")
+}
+
+// T returns the message for key in locale, formatted with args. Messages
+// missing from locale fall back to the default language, and to the key
+// itself when they are missing there too.
+func T(locale Locale, key string, args ...any) string {
+	message, ok := Lookup(locale, key)
+	if !ok {
+		if message, ok = catalogue[Default][key]; !ok {
+			message = key
+		}
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+
+	return fmt.Sprintf(message, args...)
+}
+
+// Lookup returns the unformatted message for key in locale, without
+// falling back to another language.
+func Lookup(locale Locale, key string) (message string, ok bool) {
+	message, ok = catalogue[locale][key]
+	return
+}
@@ -0,0 +1,310 @@
+package i18n
+
+// swedish is the default language, every message must be in it. Command
+// names and descriptions are written in Swedish in the commands themselves
+// and are not repeated here.
+var swedish = map[string]string{
+	"locale.name": "Svenska",
+
+	"error.title":             "Fel",
+	"error.internal":          "Intern fel",
+	"error.forbidden":         "Du har inte behörighet att göra detta",
+	"error.invalid_date":      "Ogiltigt datum, använd formatet ÅÅÅÅ-MM-DD",
+	"error.product_not_found": "Produkten hittades inte",
+	"error.user_not_found":    "Användaren finns inte",
+	"error.not_registered":    "%s är inte registrerad i systemet, registrera med /user create",
+	"error.self_unregistered": "Du är inte registrerad i systemet\nRegistrera med /user create",
+	"error.default_guild":     "Kommandot fungerar bara i huvudservern",
+
+	"help.title":          "Hjälp",
+	"help.details":        "Skriv `/help <command>` för att läsa mer om ett kommando.",
+	"help.admin_commands": "Adminkommandon",
+	"help.user_menu":      "Högerklicka på en användare",
+	"help.message_menu":   "Högerklicka på ett meddelande",
+	"help.host":           "Körs på %s %s (%s)",
+	"help.usage":          "Användning",
+	"help.notation":       "<> måste anges, [] är valfritt",
+	"help.admin_only":     "Endast för admins",
+	"help.dm_capable":     "Fungerar i direktmeddelanden",
+	"help.unknown":        "Kommandot /%s finns inte",
+
+	"strecka.receipt":           "Streckar %dst %s (%.02f)",
+	"strecka.for_self":          "%s åt dig",
+	"strecka.for_user":          "%s åt %s",
+	"strecka.failed":            "Kunde inte strecka",
+	"strecka.undo":              "Ångra",
+	"strecka.dispute":           "Bestrid",
+	"strecka.undone_by":         "Ångrat av %s",
+	"strecka.disputed_by":       "Bestritt av %s",
+	"strecka.undo_forbidden":    "Bara den som streckade kan ångra",
+	"strecka.undo_too_late":     "Det är för sent att ångra, kontakta en admin",
+	"strecka.undo_failed":       "Kunde inte ångra",
+	"strecka.repeat_forbidden":  "Bara den som streckade kan strecka igen",
+	"strecka.dispute_forbidden": "Bara den som strecket gäller kan bestrida det",
+	"strecka.dispute_gone":      "Strecket finns inte längre",
+	"strecka.dispute_failed":    "Kunde inte bestrida strecket",
+	"strecka.dispute_title":     "Bestritt streck",
+	"strecka.dispute_post":      "%s bestrider ett streck som %s gjorde",
+	"strecka.dispute_receipt":   "Streck",
+	"strecka.dispute_reverse":   "Ångra med `/reverse transaction:%d`",
+
+	"reverse.title":     "Streck ångrat",
+	"reverse.done":      "Ångrade %dst %s (%.02fkr) åt %s",
+	"reverse.not_found": "Transaktion %d finns inte eller är redan ångrad",
+	"reverse.failed":    "Kunde inte ångra strecket",
+
+	"picker.bot":          "Det går inte att strecka åt en bot",
+	"picker.failed":       "Kunde inte hämta produkterna",
+	"picker.prompt":       "Välj en produkt att strecka åt %s",
+	"picker.query":        "%s (sökning: %s)",
+	"picker.truncated":    "Visar %d av %d produkter, sök för att hitta fler",
+	"picker.empty":        "Det finns inga produkter",
+	"picker.no_match":     "Inga produkter hittades för %q",
+	"picker.option":       "%.02fkr, %dst i lager",
+	"picker.placeholder":  "Produkt",
+	"picker.search":       "Sök",
+	"picker.search_title": "Sök produkt",
+	"picker.search_label": "Produktnamn",
+	"picker.not_yours":    "Det här är inte din produktväljare",
+	"picker.done":         "Streckat åt %s",
+	"picker.no_message":   "Meddelandet hittades inte",
+
+	"balance.title":        "Saldo",
+	"balance.description":  "Saldo för %s",
+	"balance.current":      "Nuvarande",
+	"balance.credit":       "%.02fkr i kredit",
+	"balance.debt":         "%.02fkr i skuld",
+	"balance.total_debt":   "Total skuld",
+	"balance.total_credit": "Totalt saldo",
+	"balance.swish_title":  "Betala med Swish",
+	"balance.swish":        "Skanna koden eller swisha %.02fkr till %s med meddelandet `%s`",
+
+	"reminder.title":            "Påminnelse om skuld",
+	"reminder.description":      "Hej %s! Du har en skuld på **%.02fkr** i strecklistan.",
+	"reminder.footer":           "Du kan stänga av påminnelserna med /settings",
+	"reminder.swish":            "Skanna koden eller swisha till %s med meddelandet `%s`",
+	"reminder.pay_title":        "Betala",
+	"reminder.pay":              "Kontakta kassören för att betala din skuld",
+	"reminder.summary_title":    "Skuldpåminnelser",
+	"reminder.summary":          "%d påminnelser skickade, %d misslyckades",
+	"reminder.summary_reminded": "Påminda",
+	"reminder.summary_failed":   "Kunde inte nås",
+	"reminder.summary_line":     "<@%s> %.02fkr",
+	"reminder.summary_error":    "Avbröts",
+
+	"history.title":    "Historik för %s",
+	"history.failed":   "Kunde inte hämta historiken",
+	"history.empty":    "Inga streck under perioden",
+	"history.external": "%s (externt pris)",
+	"history.footer":   "Sida %d av %d, %d streck totalt",
+	"history.previous": "Föregående",
+	"history.next":     "Nästa",
+
+	"settings.title":         "Inställningar",
+	"settings.get_failed":    "Kunde inte hämta inställningarna",
+	"settings.save_failed":   "Kunde inte spara inställningarna",
+	"settings.reminders":     "Skuldpåminnelser",
+	"settings.on":            "På",
+	"settings.off":           "Av",
+	"settings.language":      "Språk",
+	"settings.language_auto": "Automatiskt (%s)",
+
+	"log.strecka_title":   "Streck",
+	"log.strecka":         "%s streckade %dst %s (%.02fkr) åt %s",
+	"log.reversal_title":  "Ångrat streck",
+	"log.reversal":        "%s ångrade %dst %s (%.02fkr) åt %s",
+	"log.stock_title":     "Lager",
+	"log.stock":           "%s lade till %dst %s köpt av %s, %dst i lager",
+	"log.product_title":   "Ny produkt",
+	"log.product":         "%s skapade %s för %s (inköp/internt/externt)",
+	"log.price_title":     "Prisändring",
+	"log.price":           "%s ändrade priset på %s från %s till %s (inköp/internt/externt)",
+	"log.prices":          "%.02f/%.02f/%.02fkr",
+	"log.payment_title":   "Betalning",
+	"log.payment":         "%s registrerade en betalning på %.02fkr från %s",
+	"log.write_off_title": "Avskrivning",
+	"log.write_off":       "%s skrev av %.02fkr av skulden för %s",
+	"log.unknown":         "Okänd",
+
+	"badge.first_strecka":             "Första strecket",
+	"badge.first_strecka_description": "Streckade för första gången",
+	"badge.hundred_cans":              "Hundra burkar",
+	"badge.hundred_cans_description":  "Har streckat 100 st",
+	"badge.every_product":             "Provsmakare",
+	"badge.every_product_description": "Har streckat varje produkt",
+	"badge.night_owl":                 "Nattuggla",
+	"badge.night_owl_description":     "Streckade mellan midnatt och fem",
+	"badge.top_restocker":             "Storhandlare",
+	"badge.top_restocker_description": "Har fyllt på mest lager av alla",
+	"badge.announcement":              "Nytt märke",
+	"badge.unlocked":                  "%s har låst upp %s **%s**: %s",
+	"badge.kiosk":                     "%s låste upp %s %s!",
+
+	"badges.title":  "Märken för %s",
+	"badges.failed": "Kunde inte hämta märkena",
+	"badges.locked": "Inte upplåst än",
+	"badges.footer": "%d av %d märken",
+
+	"product.title":                   "Produkt",
+	"product.purchase_price":          "Inköpspris",
+	"product.internal_price":          "Internpris",
+	"product.external_price":          "Externpris",
+	"product.stock":                   "Lagersaldo",
+	"product.stock_count":             "%dst",
+	"product.category":                "Kategori",
+	"product.uncategorised":           "Okategoriserad",
+	"product.category_title":          "Kategori uppdaterad",
+	"product.category_set":            "%s redovisas nu under %s",
+	"product.category_failed":         "Kunde inte sätta kategorin",
+	"product.stock_updated":           "Lagersaldo uppdaterat för %s",
+	"product.stock_failed":            "Kunde inte lägga till lagersaldo",
+	"product.price_failed":            "Kunde inte uppdatera pris",
+	"product.user_failed":             "Kunde inte hämta användare",
+	"product.buyer":                   "Inköpare",
+	"product.buyer_credit":            "%s fick %.02f kronor att handla för 😋",
+	"product.name_missing":            "Namnet saknas",
+	"product.name_too_long":           "Namnet får vara högst %d tecken",
+	"product.name_check_failed":       "Kunde inte kontrollera om namnet är upptaget",
+	"product.name_taken":              "Det finns redan en produkt som heter %s",
+	"product.negative_price":          "Priserna får inte vara negativa",
+	"product.internal_below_purchase": "Internpriset (%.02f kr) är lägre än inköpspriset (%.02f kr)",
+	"product.external_below_internal": "Externpriset (%.02f kr) är lägre än internpriset (%.02f kr)",
+	"product.negative_stock":          "Lagersaldot får inte vara negativt",
+	"product.invalid_upc":             "Streckkoden ska vara 8 till 14 siffror",
+	"product.upc_taken":               "Streckkoden används redan",
+
+	"form.new":                "Ny produkt",
+	"form.edit":               "Redigera %s",
+	"form.intro":              "Fyll i formuläret, inget sparas förrän du har bekräftat",
+	"form.open":               "Öppna formulär",
+	"form.change":             "Ändra",
+	"form.save":               "Spara",
+	"form.cancel":             "Avbryt",
+	"form.not_yours":          "Formuläret tillhör någon annan",
+	"form.name":               "Namn",
+	"form.prices":             "Priser: inköp / intern / extern",
+	"form.stock":              "Lagersaldo att lägga in",
+	"form.upc":                "Streckkod",
+	"form.upc_placeholder":    "Skapas om den lämnas tom",
+	"form.upc_auto":           "Skapas automatiskt",
+	"form.problems":           "Produkten kan inte sparas:\n- %s",
+	"form.confirm":            "Stämmer det här?",
+	"form.invalid_prices":     "Skriv priserna som inköp / intern / extern, till exempel 10 / 12,5 / 15",
+	"form.invalid_stock":      "Lagersaldot måste vara ett heltal",
+	"form.stock_unregistered": "Du måste vara registrerad för att lägga in lagersaldo, registrera med /user create",
+	"form.created":            "Produkt skapad: %s",
+	"form.add_stock":          "Dags att lägga till lagersaldo!",
+	"form.updated":            "Produkt uppdaterad: %s",
+	"form.save_failed":        "Produkten kunde inte sparas, försök igen",
+	"form.cancelled":          "Avbrutet, inget sparades",
+
+	"leaderboard.title_tonight": "Topplista ikväll",
+	"leaderboard.title_week":    "Topplista den här veckan",
+	"leaderboard.title_term":    "Topplista den här terminen",
+	"leaderboard.title_all":     "Topplista genom tiderna",
+	"leaderboard.unknown":       "Okänd period eller mått",
+	"leaderboard.failed":        "Kunde inte hämta topplistan",
+	"leaderboard.render_failed": "Kunde inte rita topplistan",
+	"leaderboard.empty":         "Ingen har streckat än",
+	"leaderboard.units":         "%.0f st",
+	"leaderboard.spend":         "%.0f kr",
+	"leaderboard.new":           "ny",
+
+	"report.title":  "Rapport %s till %s",
+	"report.failed": "Kunde inte skapa rapporten",
+	"report.more":   "%d rader till finns i bifogad fil",
+	"report.empty":  "Inget såldes under perioden",
+	"report.line":   "%d st, omsättning %.02fkr, varukostnad %.02fkr, marginal %.02fkr (%.0f%%)",
+	"report.total":  "Totalt",
+
+	"audit.title":  "Granskningslogg",
+	"audit.failed": "Kunde inte hämta granskningsloggen",
+	"audit.empty":  "Inga händelser hittades",
+	"audit.entry":  "`#%d` %s **%s** via %s",
+	"audit.by":     "av %s",
+	"audit.for":    "åt %s",
+
+	"export.failed":              "Kunde inte skapa exporten",
+	"export.transactions_failed": "Kunde inte hämta strecken",
+	"export.ledger_failed":       "Kunde inte hämta bokföringen",
+	"export.users_failed":        "Kunde inte hämta användarna",
+	"export.products_failed":     "Kunde inte hämta produkterna",
+	"export.sie":                 "SIE-fil för %s till %s",
+
+	"import.too_large":          "Filen får vara högst %d kB",
+	"import.download_failed":    "Kunde inte hämta filen",
+	"import.failed":             "Importen misslyckades, inga produkter skapades: %s",
+	"import.dry_run_title":      "Provkörning",
+	"import.done_title":         "Import klar",
+	"import.applied":            "%d av %d produkter skapades",
+	"import.rejected":           "Inget importerades, %d fel hittades",
+	"import.dry_run":            "Provkörning, %d produkter skulle skapas",
+	"import.line":               "Rad %d: %s",
+	"import.row":                "Rad %d: %s (%.02f / %.02f / %.02f kr), %d st i lager",
+	"import.empty_file":         "Filen är tom",
+	"import.missing_column":     "Kolumnen %q saknas",
+	"import.name_missing":       "name saknas",
+	"import.invalid_price":      "%s måste vara ett tal som inte är negativt",
+	"import.invalid_stock":      "stock måste vara ett heltal som inte är negativt",
+	"import.duplicate":          "%s finns redan på rad %d",
+	"import.search_failed":      "Kunde inte söka efter produkten",
+	"import.exists":             "%s finns redan som produkt %d",
+	"import.stock_user_missing": "Lagersaldo kräver en användare att kreditera",
+	"import.stock_user_unknown": "Användaren %s finns inte",
+
+	"statement.invalid_month":     "Ogiltig månad, använd formatet ÅÅÅÅ-MM",
+	"statement.get_failed":        "Kunde inte hämta kontoutdraget",
+	"statement.create_failed":     "Kunde inte skapa kontoutdraget",
+	"statement.send_failed":       "Kunde inte skicka kontoutdraget, tillåter du direktmeddelanden?",
+	"statement.dm":                "Kontoutdrag för %s",
+	"statement.sent":              "Kontoutdraget för %s har skickats som direktmeddelande",
+	"statement.get_all_failed":    "Kunde inte hämta kontoutdragen",
+	"statement.create_all_failed": "Kunde inte skapa kontoutdragen",
+	"statement.none":              "Inga kontoutdrag att skapa för %s",
+	"statement.all":               "%d kontoutdrag för %s",
+	"statement.pdf_title":         "Kontoutdrag",
+	"statement.pdf_period":        "Period: %s – %s",
+	"statement.pdf_date":          "Datum",
+	"statement.pdf_kind":          "Händelse",
+	"statement.pdf_product":       "Produkt",
+	"statement.pdf_quantity":      "Antal",
+	"statement.pdf_unit_price":    "À-pris",
+	"statement.pdf_amount":        "Belopp",
+	"statement.pdf_opening":       "Ingående saldo",
+	"statement.pdf_closing":       "Utgående saldo",
+	"statement.pdf_empty":         "Inga händelser under perioden",
+	"statement.pdf_note":          "Positivt saldo är kredit, negativt saldo är skuld.",
+	"statement.pdf_money":         "%.02f kr",
+	"statement.kind_strecka":      "Streck",
+	"statement.kind_stock":        "Inköp",
+	"statement.kind_payment":      "Inbetalning",
+
+	"jobs.title":           "Jobb",
+	"jobs.failed":          "Kunde inte hämta jobben",
+	"jobs.job":             "%s\nSchema: %s\nSenast: %s",
+	"jobs.manual":          "Endast manuellt",
+	"jobs.next":            "`%s`, nästa <t:%d:R>",
+	"jobs.never":           "Aldrig",
+	"jobs.running":         "Körs just nu",
+	"jobs.ok":              "<t:%d:f>, lyckades",
+	"jobs.failed_run":      "<t:%d:f>, misslyckades: %s",
+	"jobs.aborted":         "<t:%d:f>, avbröts",
+	"jobs.unknown":         "Det finns inget jobb som heter `%s`",
+	"jobs.already_running": "Jobbet `%s` körs redan",
+	"jobs.run_failed":      "Jobbet `%s` misslyckades: %s",
+	"jobs.done":            "Jobbet `%s` kördes",
+
+	"backup.failed":      "Kunde inte hämta säkerhetskopia: %s",
+	"backup.read_failed": "Kunde inte läsa säkerhetskopian",
+	"backup.file":        "Säkerhetskopia `%s`",
+
+	"payment.title_payment":   "Inbetalning registrerad",
+	"payment.title_write_off": "Skuld avskriven",
+	"payment.done_payment":    "Registrerade %.02fkr från %s, skulden är nu %.02fkr",
+	"payment.done_write_off":  "Skrev av %.02fkr av skulden för %s, skulden är nu %.02fkr",
+	"payment.failed":          "Kunde inte registrera betalningen",
+
+	"user.exists":        "Användaren finns redan",
+	"user.create_failed": "Kunde inte skapa användaren",
+	"user.created":       "Användaren %s har skapats",
+}
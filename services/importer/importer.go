@@ -0,0 +1,258 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"gostrecka/models"
+	"gostrecka/services/database"
+	"gostrecka/services/i18n"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProductRow is a product to create, read from line Line of the file.
+type ProductRow struct {
+	Line          int
+	Name          string
+	PurchasePrice float64
+	InternalPrice float64
+	ExternalPrice float64
+	Stock         int64
+}
+
+// RowError is a problem with line Line of the file, or with the whole file
+// when Line is zero. Its message is "import." and Key in the catalogue,
+// formatted with Args.
+type RowError struct {
+	Line int
+	Key  string
+	Args []any
+}
+
+// Message returns what is wrong in locale.
+func (e RowError) Message(locale i18n.Locale) string {
+	return i18n.T(locale, "import."+e.Key, e.Args...)
+}
+
+// Report describes what an import did, or would do when Applied is false.
+type Report struct {
+	Rows    []ProductRow
+	Errors  []RowError
+	Created int
+	Applied bool
+}
+
+var requiredColumns = []string{"name", "purchase_price", "internal_price", "external_price"}
+
+// ImportProducts reads products from CSV with the columns name,
+// purchase_price, internal_price, external_price and an optional stock,
+// which is credited to stockUser. Nothing is written unless apply is set
+// and every row is valid, and then every product is created in one
+// transaction.
+func ImportProducts(db database.Database, origin models.Origin, stockUser string, r io.Reader, apply bool) (report Report, err error) {
+	report.Rows, report.Errors, err = parseProducts(r)
+	if err != nil {
+		return
+	}
+
+	report.Errors = append(report.Errors, validate(db, stockUser, report.Rows)...)
+	sort.SliceStable(report.Errors, func(i, j int) bool {
+		return report.Errors[i].Line < report.Errors[j].Line
+	})
+
+	if !apply || len(report.Errors) > 0 {
+		return
+	}
+
+	products := make([]models.NewProduct, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		products = append(products, models.NewProduct{
+			Name:          row.Name,
+			PurchasePrice: row.PurchasePrice,
+			InternalPrice: row.InternalPrice,
+			ExternalPrice: row.ExternalPrice,
+			Stock:         row.Stock,
+		})
+	}
+
+	ids, err := db.CreateProducts(origin, products, stockUser)
+	if err != nil {
+		return
+	}
+
+	report.Applied = true
+	report.Created = len(ids)
+
+	return
+}
+
+func parseProducts(r io.Reader) (rows []ProductRow, errs []RowError, err error) {
+	buffered := bufio.NewReader(r)
+
+	// Spreadsheets with a Swedish locale separate columns with semicolons,
+	// since the comma is the decimal separator.
+	header, err := buffered.Peek(min(buffered.Size(), 512))
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, nil, err
+	}
+
+	reader := csv.NewReader(buffered)
+	firstLine, _, _ := strings.Cut(string(header), "\n")
+	if strings.Contains(firstLine, ";") && !strings.Contains(firstLine, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, []RowError{{Key: "empty_file"}}, nil
+	}
+
+	columns := make(map[string]int)
+	for i, column := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))] = i
+	}
+	for _, column := range requiredColumns {
+		if _, ok := columns[column]; !ok {
+			errs = append(errs, RowError{Key: "missing_column", Args: []any{column}})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs, nil
+	}
+
+	for i, record := range records[1:] {
+		line := i + 2
+		field := func(name string) string {
+			index, ok := columns[name]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		row := ProductRow{Line: line, Name: field("name")}
+		var rowErrs []RowError
+
+		if row.Name == "" {
+			rowErrs = append(rowErrs, RowError{Line: line, Key: "name_missing"})
+		}
+
+		for _, price := range []struct {
+			column string
+			value  *float64
+		}{
+			{"purchase_price", &row.PurchasePrice},
+			{"internal_price", &row.InternalPrice},
+			{"external_price", &row.ExternalPrice},
+		} {
+			value, err := strconv.ParseFloat(strings.ReplaceAll(field(price.column), ",", "."), 64)
+			if err != nil || value < 0 {
+				rowErrs = append(rowErrs, RowError{Line: line, Key: "invalid_price", Args: []any{price.column}})
+				continue
+			}
+			*price.value = value
+		}
+
+		if stock := field("stock"); stock != "" {
+			value, err := strconv.ParseInt(stock, 10, 64)
+			if err != nil || value < 0 {
+				rowErrs = append(rowErrs, RowError{Line: line, Key: "invalid_stock"})
+			} else {
+				row.Stock = value
+			}
+		}
+
+		if len(rowErrs) > 0 {
+			errs = append(errs, rowErrs...)
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, errs, nil
+}
+
+func validate(db database.Database, stockUser string, rows []ProductRow) (errs []RowError) {
+	seen := make(map[string]int)
+
+	needsStockUser := false
+	for _, row := range rows {
+		name := strings.ToLower(row.Name)
+
+		if line, ok := seen[name]; ok {
+			errs = append(errs, RowError{Line: row.Line, Key: "duplicate", Args: []any{row.Name, line}})
+			continue
+		}
+		seen[name] = row.Line
+
+		existing, err := db.SearchProduct(row.Name)
+		if err != nil {
+			errs = append(errs, RowError{Line: row.Line, Key: "search_failed"})
+			continue
+		}
+		for _, product := range existing {
+			if strings.EqualFold(product.Product.Name, row.Name) {
+				errs = append(errs, RowError{Line: row.Line, Key: "exists", Args: []any{row.Name, product.Product.ID}})
+				break
+			}
+		}
+
+		if row.Stock > 0 {
+			needsStockUser = true
+		}
+	}
+
+	if needsStockUser {
+		if stockUser == "" {
+			errs = append(errs, RowError{Key: "stock_user_missing"})
+		} else if _, _, err := db.GetUser(stockUser); err != nil {
+			errs = append(errs, RowError{Key: "stock_user_unknown", Args: []any{stockUser}})
+		}
+	}
+
+	return
+}
+
+// Format renders the report for people to read in locale.
+func (r Report) Format(locale i18n.Locale) string {
+	var b strings.Builder
+
+	switch {
+	case r.Applied:
+		b.WriteString(i18n.T(locale, "import.applied", r.Created, len(r.Rows)) + "\n")
+	case len(r.Errors) > 0:
+		b.WriteString(i18n.T(locale, "import.rejected", len(r.Errors)) + "\n")
+	default:
+		b.WriteString(i18n.T(locale, "import.dry_run", len(r.Rows)) + "\n")
+	}
+
+	for _, e := range r.Errors {
+		if e.Line > 0 {
+			b.WriteString(i18n.T(locale, "import.line", e.Line, e.Message(locale)) + "\n")
+		} else {
+			b.WriteString(e.Message(locale) + "\n")
+		}
+	}
+
+	if !r.Applied && len(r.Errors) == 0 {
+		for _, row := range r.Rows {
+			b.WriteString(i18n.T(locale, "import.row", row.Line, row.Name,
+				row.PurchasePrice, row.InternalPrice, row.ExternalPrice, row.Stock) + "\n")
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,136 @@
+package leaderboard
+
+import (
+	"errors"
+	"fmt"
+	"gostrecka/models"
+	"gostrecka/services/database"
+	"gostrecka/services/env"
+	"time"
+)
+
+var (
+	ErrUnknownWindow = errors.New("unknown leaderboard window")
+	ErrUnknownMetric = errors.New("unknown leaderboard metric")
+)
+
+// Default returns the kiosk's leaderboard from the config.
+func Default(config env.LeaderboardConfig) models.LeaderboardQuery {
+	return models.LeaderboardQuery{
+		Window:    config.Window,
+		Metric:    config.Metric,
+		ProductID: config.ProductID,
+		Category:  config.Category,
+	}
+}
+
+// Get returns the leaderboard described by query. Users are never nil so
+// an empty leaderboard encodes as an empty list.
+func Get(db database.Database, config env.LeaderboardConfig, query models.LeaderboardQuery) ([]models.TransactionLeaderboard, error) {
+	filter, err := Filter(config, query, time.Now())
+	if err != nil {
+		return []models.TransactionLeaderboard{}, err
+	}
+
+	leaderboard, err := db.GetTransactionLeaderboard(filter)
+	if leaderboard == nil {
+		leaderboard = []models.TransactionLeaderboard{}
+	}
+
+	return leaderboard, err
+}
+
+// Latest returns the transactions behind the leaderboard described by
+// query, with each user's running totals, for charting.
+func Latest(db database.Database, config env.LeaderboardConfig, query models.LeaderboardQuery) ([]models.LatestTransaction, error) {
+	filter, err := Filter(config, query, time.Now())
+	if err != nil {
+		return []models.LatestTransaction{}, err
+	}
+
+	transactions, err := db.GetLatestTransactions(filter)
+	if transactions == nil {
+		transactions = []models.LatestTransaction{}
+	}
+
+	return transactions, err
+}
+
+// Filter resolves the window of query relative to now.
+func Filter(config env.LeaderboardConfig, query models.LeaderboardQuery, now time.Time) (filter models.LeaderboardFilter, err error) {
+	switch query.Metric {
+	case models.LeaderboardUnits, models.LeaderboardSpend:
+	default:
+		return filter, ErrUnknownMetric
+	}
+
+	change, err := duration(config.Change, 15*time.Minute)
+	if err != nil {
+		return filter, fmt.Errorf("invalid leaderboard change: %w", err)
+	}
+
+	filter = models.LeaderboardFilter{
+		Compare:   now.Add(-change),
+		Metric:    query.Metric,
+		ProductID: query.ProductID,
+		Category:  query.Category,
+	}
+
+	now = now.In(time.Local)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	switch query.Window {
+	case models.LeaderboardTonight:
+		tonight, err := duration(config.Tonight, 12*time.Hour)
+		if err != nil {
+			return filter, fmt.Errorf("invalid leaderboard tonight: %w", err)
+		}
+		filter.From = now.Add(-tonight)
+	case models.LeaderboardWeek:
+		// Weeks start on Monday.
+		filter.From = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	case models.LeaderboardTerm:
+		if filter.From, err = termStart(config.TermStarts, today); err != nil {
+			return filter, err
+		}
+	case models.LeaderboardAllTime:
+	default:
+		return filter, ErrUnknownWindow
+	}
+
+	return filter, nil
+}
+
+// termStart returns the latest configured term start on or before today.
+// Without any term starts the term is the calendar year.
+func termStart(starts []string, today time.Time) (start time.Time, err error) {
+	start = time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, time.Local)
+	if len(starts) == 0 {
+		return start, nil
+	}
+
+	start = time.Time{}
+	for _, s := range starts {
+		day, err := time.ParseInLocation("01-02", s, time.Local)
+		if err != nil {
+			return start, fmt.Errorf("invalid term start %q: %w", s, err)
+		}
+
+		for _, year := range []int{today.Year(), today.Year() - 1} {
+			candidate := time.Date(year, day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+			if !candidate.After(today) && candidate.After(start) {
+				start = candidate
+			}
+		}
+	}
+
+	return start, nil
+}
+
+func duration(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	return time.ParseDuration(value)
+}
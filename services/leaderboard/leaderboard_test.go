@@ -0,0 +1,136 @@
+package leaderboard
+
+import (
+	"errors"
+	"gostrecka/models"
+	"gostrecka/services/env"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.Local)
+}
+
+func TestFilterWindows(t *testing.T) {
+	config := env.DefaultConfig().Leaderboard
+	config.Tonight = "6h"
+	config.Change = "30m"
+	config.TermStarts = []string{"01-15", "08-15"}
+
+	// Monday 2026-10-19 at 20:00.
+	now := date(2026, time.October, 19, 20)
+
+	tests := []struct {
+		window string
+		now    time.Time
+		want   time.Time
+	}{
+		{models.LeaderboardTonight, now, date(2026, time.October, 19, 14)},
+		{models.LeaderboardWeek, now, date(2026, time.October, 19, 0)},
+		{models.LeaderboardWeek, date(2026, time.October, 25, 23), date(2026, time.October, 19, 0)},
+		{models.LeaderboardWeek, date(2026, time.October, 21, 8), date(2026, time.October, 19, 0)},
+		{models.LeaderboardTerm, now, date(2026, time.August, 15, 0)},
+		{models.LeaderboardAllTime, now, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.window+" "+tt.now.Weekday().String(), func(t *testing.T) {
+			filter, err := Filter(config, models.LeaderboardQuery{Window: tt.window, Metric: models.LeaderboardSpend, ProductID: 3}, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !filter.From.Equal(tt.want) {
+				t.Errorf("From = %v, want %v", filter.From, tt.want)
+			}
+			if want := tt.now.Add(-30 * time.Minute); !filter.Compare.Equal(want) {
+				t.Errorf("Compare = %v, want %v", filter.Compare, want)
+			}
+			if filter.Metric != models.LeaderboardSpend || filter.ProductID != 3 {
+				t.Errorf("query not carried over: %+v", filter)
+			}
+		})
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	config := env.DefaultConfig().Leaderboard
+	now := date(2026, time.October, 19, 20)
+
+	tests := []struct {
+		name   string
+		config func(*env.LeaderboardConfig)
+		query  models.LeaderboardQuery
+		want   error
+	}{
+		{"unknown window", nil, models.LeaderboardQuery{Window: "year", Metric: models.LeaderboardUnits}, ErrUnknownWindow},
+		{"unknown metric", nil, models.LeaderboardQuery{Window: models.LeaderboardWeek, Metric: "volume"}, ErrUnknownMetric},
+		{"invalid change", func(c *env.LeaderboardConfig) { c.Change = "soon" }, models.LeaderboardQuery{Window: models.LeaderboardWeek, Metric: models.LeaderboardUnits}, nil},
+		{"invalid tonight", func(c *env.LeaderboardConfig) { c.Tonight = "12" }, models.LeaderboardQuery{Window: models.LeaderboardTonight, Metric: models.LeaderboardUnits}, nil},
+		{"invalid term start", func(c *env.LeaderboardConfig) { c.TermStarts = []string{"13-01"} }, models.LeaderboardQuery{Window: models.LeaderboardTerm, Metric: models.LeaderboardUnits}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config
+			if tt.config != nil {
+				tt.config(&c)
+			}
+
+			_, err := Filter(c, tt.query, now)
+			if err == nil {
+				t.Fatal("Filter() succeeded, want an error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Filter() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterDefaultDurations(t *testing.T) {
+	config := env.LeaderboardConfig{}
+	now := date(2026, time.October, 19, 20)
+
+	filter, err := Filter(config, models.LeaderboardQuery{Window: models.LeaderboardTonight, Metric: models.LeaderboardUnits}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !filter.From.Equal(now.Add(-12 * time.Hour)) {
+		t.Errorf("From = %v, want 12 hours back", filter.From)
+	}
+	if !filter.Compare.Equal(now.Add(-15 * time.Minute)) {
+		t.Errorf("Compare = %v, want 15 minutes back", filter.Compare)
+	}
+}
+
+func TestTermStart(t *testing.T) {
+	tests := []struct {
+		name   string
+		starts []string
+		today  time.Time
+		want   time.Time
+	}{
+		{"no starts", nil, date(2026, time.March, 3, 0), date(2026, time.January, 1, 0)},
+		{"spring term", []string{"01-15", "08-15"}, date(2026, time.March, 3, 0), date(2026, time.January, 15, 0)},
+		{"autumn term", []string{"01-15", "08-15"}, date(2026, time.October, 19, 0), date(2026, time.August, 15, 0)},
+		{"on the start day", []string{"01-15", "08-15"}, date(2026, time.August, 15, 0), date(2026, time.August, 15, 0)},
+		{"across the year boundary", []string{"01-15", "08-15"}, date(2026, time.January, 10, 0), date(2025, time.August, 15, 0)},
+		{"unordered starts", []string{"08-15", "01-15"}, date(2026, time.January, 10, 0), date(2025, time.August, 15, 0)},
+		{"single start", []string{"09-01"}, date(2026, time.June, 1, 0), date(2025, time.September, 1, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := termStart(tt.starts, tt.today)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("termStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
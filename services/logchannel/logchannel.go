@@ -0,0 +1,220 @@
+package logchannel
+
+import (
+	"fmt"
+	"gostrecka/internal/utils/static"
+	"gostrecka/models"
+	"gostrecka/services/env"
+	"gostrecka/services/events"
+	"gostrecka/services/i18n"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/sarulabs/di/v2"
+)
+
+// maxEmbeds is the most embeds Discord allows in one message.
+const maxEmbeds = 10
+
+// defaultBatch is used when the configured batch duration is invalid.
+const defaultBatch = 5 * time.Second
+
+// origins are the names origins are shown with.
+var origins = map[string]string{
+	models.OriginDiscord: "Discord",
+	models.OriginKiosk:   "Kiosk",
+	models.OriginCli:     "CLI",
+	models.OriginApi:     "API",
+	models.OriginSystem:  "System",
+}
+
+// LogChannelService posts every strecka, stock addition, price change,
+// payment and reversal to the log channel of the guild it was made in, so
+// treasurers can follow the ledger outside of the database.
+type LogChannelService struct {
+	container di.Container
+	logger    *slog.Logger
+	config    env.Config
+	locale    i18n.Locale
+
+	mu sync.Mutex
+	// pending are the embeds waiting to be posted, by channel.
+	pending map[string][]*discordgo.MessageEmbed
+}
+
+func New(container di.Container) *LogChannelService {
+	config := container.Get(static.DiConfig).(env.Config)
+
+	return &LogChannelService{
+		container: container,
+		logger:    container.Get("logger").(*slog.Logger).With("service", "LOGCHANNEL"),
+		config:    config,
+		locale:    i18n.Resolve(config.Locale),
+		pending:   map[string][]*discordgo.MessageEmbed{},
+	}
+}
+
+// Enabled reports whether a log channel is configured for any guild.
+func (s *LogChannelService) Enabled() bool {
+	if s.config.Log.Channel != "" {
+		return true
+	}
+
+	for _, guild := range s.config.Guilds {
+		if guild.LogChannel != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Batch returns how long changes are collected before they are posted.
+func (s *LogChannelService) Batch() time.Duration {
+	batch, err := time.ParseDuration(s.config.Log.Batch)
+	if err != nil || batch <= 0 {
+		s.logger.Error("invalid log batch duration", "batch", s.config.Log.Batch, "error", err)
+		return defaultBatch
+	}
+
+	return batch
+}
+
+// Start posts the changes published on the event bus until the process
+// exits. Changes are posted once per batch, with up to ten in a message,
+// so a busy evening takes a few messages rather than one per strecka. It
+// returns immediately when no log channel is configured.
+func (s *LogChannelService) Start() {
+	if !s.Enabled() {
+		return
+	}
+
+	bus := s.container.Get(static.DiEvents).(*events.Bus)
+	bus.SubscribeAll("logchannel", s.onEvent)
+
+	ticker := time.NewTicker(s.Batch())
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.flush()
+	}
+}
+
+func (s *LogChannelService) onEvent(event events.Event) {
+	guild, embed := s.embed(event)
+	if embed == nil {
+		return
+	}
+
+	channel := s.config.LogChannel(guild)
+	if channel == "" {
+		return
+	}
+
+	s.mu.Lock()
+	s.pending[channel] = append(s.pending[channel], embed)
+	s.mu.Unlock()
+}
+
+func (s *LogChannelService) flush() {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = map[string][]*discordgo.MessageEmbed{}
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	session, err := s.container.SafeGet(static.DiDiscordSession)
+	if err != nil {
+		s.logger.Warn("no Discord session, dropping log entries", "error", err)
+		return
+	}
+
+	for channel, embeds := range pending {
+		for len(embeds) > 0 {
+			n := min(len(embeds), maxEmbeds)
+			if _, err := session.(*discordgo.Session).ChannelMessageSendEmbeds(channel, embeds[:n]); err != nil {
+				s.logger.Error("Could not post to the log channel", "channel", channel, "entries", n, "error", err)
+			}
+			embeds = embeds[n:]
+		}
+	}
+}
+
+// embed describes a change to the ledger and returns the guild it was made
+// in, or a nil embed for events that are not logged.
+func (s *LogChannelService) embed(event events.Event) (guild string, embed *discordgo.MessageEmbed) {
+	switch e := event.(type) {
+	case events.TransactionCreated:
+		embed = s.entry("log.strecka", e.Origin, e.TransactionID, e.CreatedAt,
+			s.actor(e.Origin), e.Quantity, e.ProductName, e.PricePaid*float64(e.Quantity), mention(e.UserID))
+		return e.Guild, embed
+	case events.TransactionReversed:
+		embed = s.entry("log.reversal", e.Origin, e.TransactionID, e.CreatedAt,
+			s.actor(e.Origin), e.Quantity, e.ProductName, e.PricePaid*float64(e.Quantity), mention(e.UserID))
+		return e.Guild, embed
+	case events.StockAdded:
+		embed = s.entry("log.stock", e.Origin, e.StockID, e.CreatedAt,
+			s.actor(e.Origin), e.Quantity, e.ProductName, mention(e.UserID), e.TotalStock)
+		return e.Guild, embed
+	case events.PriceChanged:
+		if e.Before == nil {
+			embed = s.entry("log.product", e.Origin, e.ProductID, e.CreatedAt,
+				s.actor(e.Origin), e.ProductName, s.price(e.After))
+		} else {
+			embed = s.entry("log.price", e.Origin, e.ProductID, e.CreatedAt,
+				s.actor(e.Origin), e.ProductName, s.price(*e.Before), s.price(e.After))
+		}
+		return e.Guild, embed
+	case events.PaymentRecorded:
+		key := "log.payment"
+		if e.PaymentType == models.LedgerWriteOff {
+			key = "log.write_off"
+		}
+		embed = s.entry(key, e.Origin, e.PaymentID, e.CreatedAt,
+			s.actor(e.Origin), e.Amount, mention(e.UserID))
+		return e.Guild, embed
+	}
+
+	return "", nil
+}
+
+// entry returns an embed titled by key+"_title" with the message of key,
+// and the origin and id of the change in the footer.
+func (s *LogChannelService) entry(key string, origin models.Origin, id int64, at time.Time, args ...any) *discordgo.MessageEmbed {
+	source, ok := origins[origin.Source]
+	if !ok {
+		source = origin.Source
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       i18n.T(s.locale, key+"_title"),
+		Description: i18n.T(s.locale, key, args...),
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("%s · #%d", source, id)},
+		Timestamp:   at.Format(time.RFC3339),
+	}
+}
+
+// actor returns who made a change, or where it was made when no user did.
+func (s *LogChannelService) actor(origin models.Origin) string {
+	if origin.ActorID != "" {
+		return mention(origin.ActorID)
+	}
+	if source, ok := origins[origin.Source]; ok {
+		return source
+	}
+
+	return i18n.T(s.locale, "log.unknown")
+}
+
+func (s *LogChannelService) price(price models.ProductPrice) string {
+	return i18n.T(s.locale, "log.prices", price.PurchasePrice, price.InternalPrice, price.ExternalPrice)
+}
+
+func mention(userId string) string {
+	return "<@" + userId + ">"
+}
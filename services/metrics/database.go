@@ -0,0 +1,321 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"gostrecka/models"
+	"gostrecka/services/database"
+	"strings"
+	"time"
+)
+
+// instrumentedDatabase records the duration and errors of every call to
+// the wrapped Database. It does not embed the interface, so a method added
+// to Database fails the build until it is wrapped here too.
+type instrumentedDatabase struct {
+	db      database.Database
+	metrics *MetricsService
+}
+
+var _ database.Database = (*instrumentedDatabase)(nil)
+
+// Instrument wraps db so its calls are recorded.
+func (m *MetricsService) Instrument(db database.Database) database.Database {
+	return &instrumentedDatabase{db: db, metrics: m}
+}
+
+func (d *instrumentedDatabase) observe(method string, start time.Time, err error) {
+	d.metrics.queryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+
+	// A missing row is a normal result for lookups, but a failure for
+	// anything that changes the ledger.
+	if err == nil || (errors.Is(err, sql.ErrNoRows) && strings.HasPrefix(method, "Get")) {
+		return
+	}
+
+	d.metrics.queryErrors.WithLabelValues(method).Inc()
+}
+
+func (d *instrumentedDatabase) Connect() (err error) {
+	start := time.Now()
+	err = d.db.Connect()
+	d.observe("Connect", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) Close() {
+	d.db.Close()
+}
+
+func (d *instrumentedDatabase) Status() (err error) {
+	start := time.Now()
+	err = d.db.Status()
+	d.observe("Status", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) Backup(path string) (err error) {
+	start := time.Now()
+	err = d.db.Backup(path)
+	d.observe("Backup", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetUser(id string) (user models.User, balance models.Balance, err error) {
+	start := time.Now()
+	user, balance, err = d.db.GetUser(id)
+	d.observe("GetUser", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetUsers() (users []models.UserWithBalance, err error) {
+	start := time.Now()
+	users, err = d.db.GetUsers()
+	d.observe("GetUsers", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) CreateUser(origin models.Origin, id string, name string) (err error) {
+	start := time.Now()
+	err = d.db.CreateUser(origin, id, name)
+	d.observe("CreateUser", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetProductIdent(id int64) (product models.Product, price models.ProductPrice, err error) {
+	start := time.Now()
+	product, price, err = d.db.GetProductIdent(id)
+	d.observe("GetProductIdent", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) SearchProduct(name string) (products []models.ProductWithPrice, err error) {
+	start := time.Now()
+	products, err = d.db.SearchProduct(name)
+	d.observe("SearchProduct", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) CreateProduct(origin models.Origin, name string, purchasePrice float64, internalPrice float64, externalPrice float64) (id int64, err error) {
+	start := time.Now()
+	id, err = d.db.CreateProduct(origin, name, purchasePrice, internalPrice, externalPrice)
+	d.observe("CreateProduct", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) CreateProducts(origin models.Origin, products []models.NewProduct, stockUser string) (ids []int64, err error) {
+	start := time.Now()
+	ids, err = d.db.CreateProducts(origin, products, stockUser)
+	d.observe("CreateProducts", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) UpdatePrice(origin models.Origin, productId int64, purchasePrice float64, internalPrice float64, externalPrice float64) (err error) {
+	start := time.Now()
+	err = d.db.UpdatePrice(origin, productId, purchasePrice, internalPrice, externalPrice)
+	d.observe("UpdatePrice", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) SetProductCategory(origin models.Origin, productId int64, category string) (err error) {
+	start := time.Now()
+	err = d.db.SetProductCategory(origin, productId, category)
+	d.observe("SetProductCategory", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) RenameProduct(origin models.Origin, productId int64, name string) (err error) {
+	start := time.Now()
+	err = d.db.RenameProduct(origin, productId, name)
+	d.observe("RenameProduct", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) SetProductUpc(origin models.Origin, productId int64, upc string) (err error) {
+	start := time.Now()
+	err = d.db.SetProductUpc(origin, productId, upc)
+	d.observe("SetProductUpc", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) AddStock(origin models.Origin, productId int64, userId string, amount int64) (err error) {
+	start := time.Now()
+	err = d.db.AddStock(origin, productId, userId, amount)
+	d.observe("AddStock", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) RecordPayment(origin models.Origin, userId string, amount float64, paymentType string) (paymentId int64, err error) {
+	start := time.Now()
+	paymentId, err = d.db.RecordPayment(origin, userId, amount, paymentType)
+	d.observe("RecordPayment", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetUpcType(upc string) (lookup models.UpcLookup, err error) {
+	start := time.Now()
+	lookup, err = d.db.GetUpcType(upc)
+	d.observe("GetUpcType", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetUserUpcs() (upcs []models.Upc, err error) {
+	start := time.Now()
+	upcs, err = d.db.GetUserUpcs()
+	d.observe("GetUserUpcs", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetProductUpcs() (upcs []models.Upc, err error) {
+	start := time.Now()
+	upcs, err = d.db.GetProductUpcs()
+	d.observe("GetProductUpcs", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) Strecka(origin models.Origin, user models.User, productId int64, amount int64) (transactionId int64, err error) {
+	start := time.Now()
+	transactionId, err = d.db.Strecka(origin, user, productId, amount)
+	d.observe("Strecka", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetTransaction(transactionId int64) (transaction models.TransactionRecord, err error) {
+	start := time.Now()
+	transaction, err = d.db.GetTransaction(transactionId)
+	d.observe("GetTransaction", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) ReverseTransaction(origin models.Origin, transactionId int64) (err error) {
+	start := time.Now()
+	err = d.db.ReverseTransaction(origin, transactionId)
+	d.observe("ReverseTransaction", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) DisputeTransaction(origin models.Origin, transactionId int64) (err error) {
+	start := time.Now()
+	err = d.db.DisputeTransaction(origin, transactionId)
+	d.observe("DisputeTransaction", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetLatestTransactions(filter models.LeaderboardFilter) (transactions []models.LatestTransaction, err error) {
+	start := time.Now()
+	transactions, err = d.db.GetLatestTransactions(filter)
+	d.observe("GetLatestTransactions", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetTransactionLeaderboard(filter models.LeaderboardFilter) (leaderboard []models.TransactionLeaderboard, err error) {
+	start := time.Now()
+	leaderboard, err = d.db.GetTransactionLeaderboard(filter)
+	d.observe("GetTransactionLeaderboard", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetAuditLog(filter models.AuditFilter) (entries []models.AuditEntry, err error) {
+	start := time.Now()
+	entries, err = d.db.GetAuditLog(filter)
+	d.observe("GetAuditLog", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetStatement(userId string, from time.Time, to time.Time) (statement models.Statement, err error) {
+	start := time.Now()
+	statement, err = d.db.GetStatement(userId, from, to)
+	d.observe("GetStatement", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetLedger(from time.Time, to time.Time) (entries []models.LedgerEntry, err error) {
+	start := time.Now()
+	entries, err = d.db.GetLedger(from, to)
+	d.observe("GetLedger", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetSales(from time.Time, to time.Time) (sales []models.ProductSales, err error) {
+	start := time.Now()
+	sales, err = d.db.GetSales(from, to)
+	d.observe("GetSales", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetUserSettings(userId string) (settings models.UserSettings, err error) {
+	start := time.Now()
+	settings, err = d.db.GetUserSettings(userId)
+	d.observe("GetUserSettings", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) UpdateUserSettings(origin models.Origin, settings models.UserSettings) (err error) {
+	start := time.Now()
+	err = d.db.UpdateUserSettings(origin, settings)
+	d.observe("UpdateUserSettings", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) MarkReminded(userId string, at time.Time) (err error) {
+	start := time.Now()
+	err = d.db.MarkReminded(userId, at)
+	d.observe("MarkReminded", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetJobRuns() (runs []models.JobRun, err error) {
+	start := time.Now()
+	runs, err = d.db.GetJobRuns()
+	d.observe("GetJobRuns", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) SaveJobRun(run models.JobRun) (err error) {
+	start := time.Now()
+	err = d.db.SaveJobRun(run)
+	d.observe("SaveJobRun", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetPriceHistory() (prices []models.ProductWithPrice, err error) {
+	start := time.Now()
+	prices, err = d.db.GetPriceHistory()
+	d.observe("GetPriceHistory", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetTransactions(from time.Time, to time.Time) (transactions []models.TransactionRecord, err error) {
+	start := time.Now()
+	transactions, err = d.db.GetTransactions(from, to)
+	d.observe("GetTransactions", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetUserTransactions(filter models.TransactionFilter) (transactions []models.TransactionRecord, total int, err error) {
+	start := time.Now()
+	transactions, total, err = d.db.GetUserTransactions(filter)
+	d.observe("GetUserTransactions", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetBadges(userId string) (badges []models.Badge, err error) {
+	start := time.Now()
+	badges, err = d.db.GetBadges(userId)
+	d.observe("GetBadges", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) GetBadgeStats(userId string) (stats models.BadgeStats, err error) {
+	start := time.Now()
+	stats, err = d.db.GetBadgeStats(userId)
+	d.observe("GetBadgeStats", start, err)
+	return
+}
+
+func (d *instrumentedDatabase) UnlockBadge(userId string, badge string, at time.Time) (unlocked bool, err error) {
+	start := time.Now()
+	unlocked, err = d.db.UnlockBadge(userId, badge, at)
+	d.observe("UnlockBadge", start, err)
+	return
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zekrotja/ken"
+)
+
+const commandStartKey = "metrics_command_start"
+
+// CommandMiddleware records the latency and outcome of every ken command.
+type CommandMiddleware struct {
+	metrics *MetricsService
+}
+
+var (
+	_ ken.MiddlewareBefore = (*CommandMiddleware)(nil)
+	_ ken.MiddlewareAfter  = (*CommandMiddleware)(nil)
+)
+
+func (m *MetricsService) CommandMiddleware() *CommandMiddleware {
+	return &CommandMiddleware{metrics: m}
+}
+
+func (c *CommandMiddleware) Before(ctx *ken.Ctx) (next bool, err error) {
+	ctx.Set(commandStartKey, time.Now())
+	return true, nil
+}
+
+func (c *CommandMiddleware) After(ctx *ken.Ctx, cmdError error) (err error) {
+	// Read the object map directly, ctx.Get falls back to the container
+	// which panics on unknown keys.
+	start, ok := ctx.ObjectMap.Get(commandStartKey).(time.Time)
+	if !ok {
+		return nil
+	}
+
+	name := ctx.GetCommand().Name()
+	status := "ok"
+	if cmdError != nil {
+		status = "error"
+	}
+
+	c.metrics.commands.WithLabelValues(name, status).Inc()
+	c.metrics.commandDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+
+	return nil
+}
+
+// WatchSession tracks the gateway connection state and heartbeat latency
+// of session.
+func (m *MetricsService) WatchSession(session *discordgo.Session) {
+	session.AddHandler(func(s *discordgo.Session, _ *discordgo.Connect) {
+		m.gateway.Set(1)
+	})
+	session.AddHandler(func(s *discordgo.Session, _ *discordgo.Resumed) {
+		m.gateway.Set(1)
+	})
+	session.AddHandler(func(s *discordgo.Session, _ *discordgo.Disconnect) {
+		m.gateway.Set(0)
+	})
+
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "discord_heartbeat_latency_seconds",
+		Help:      "Latency of the last Discord gateway heartbeat.",
+	}, func() float64 {
+		return session.HeartbeatLatency().Seconds()
+	}))
+}
@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"gostrecka/internal/utils/static"
+	"gostrecka/services/env"
+	"gostrecka/services/events"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sarulabs/di/v2"
+)
+
+const namespace = "jamkstrecka"
+
+type MetricsService struct {
+	container di.Container
+	logger    *slog.Logger
+	config    env.MetricsConfig
+	registry  *prometheus.Registry
+	server    *http.Server
+
+	commands        *prometheus.CounterVec
+	commandDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+	queryErrors     *prometheus.CounterVec
+	scans           *prometheus.CounterVec
+	sales           *prometheus.CounterVec
+	revenue         *prometheus.CounterVec
+	gateway         prometheus.Gauge
+}
+
+func New(container di.Container) *MetricsService {
+	config := container.Get(static.DiConfig).(env.Config).Metrics
+
+	m := &MetricsService{
+		container: container,
+		logger:    container.Get("logger").(*slog.Logger).With("service", "METRICS"),
+		config:    config,
+		registry:  prometheus.NewRegistry(),
+
+		commands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "discord_commands_total",
+			Help:      "Number of executed Discord commands.",
+		}, []string{"command", "status"}),
+		commandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "discord_command_duration_seconds",
+			Help:      "Time taken to execute Discord commands.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"command"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "database_call_duration_seconds",
+			Help:      "Time taken by database calls.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"method"}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "database_errors_total",
+			Help:      "Number of failed database calls.",
+		}, []string{"method"}),
+		scans: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "kiosk_scans_total",
+			Help:      "Number of barcodes scanned at the kiosk, by what they resolved to.",
+		}, []string{"type"}),
+		sales: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sales_total",
+			Help:      "Number of sold items per product.",
+		}, []string{"product_id", "product", "source"}),
+		revenue: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sales_amount_sek_total",
+			Help:      "Amount charged for sold items per product.",
+		}, []string{"product_id", "product"}),
+		gateway: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "discord_gateway_connected",
+			Help:      "Whether the Discord gateway connection is up.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.commands,
+		m.commandDuration,
+		m.queryDuration,
+		m.queryErrors,
+		m.scans,
+		m.sales,
+		m.revenue,
+		m.gateway,
+		&stockCollector{metrics: m},
+	)
+
+	if config.Enabled {
+		bus := container.Get(static.DiEvents).(*events.Bus)
+		events.Subscribe(bus, "metrics", m.onTransaction)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	m.server = &http.Server{
+		Addr:              config.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return m
+}
+
+// Enabled reports whether metrics are turned on in the config.
+func (m *MetricsService) Enabled() bool {
+	return m.config.Enabled
+}
+
+// Start serves the metrics endpoint until Close is called. It returns
+// immediately when metrics are disabled.
+func (m *MetricsService) Start() {
+	if !m.config.Enabled {
+		return
+	}
+
+	m.logger.Info("Metrics listening", "address", m.config.Listen)
+	err := m.server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		m.logger.Error("Metrics server stopped", "error", err)
+	}
+}
+
+func (m *MetricsService) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return m.server.Shutdown(ctx)
+}
+
+// ObserveScan counts a kiosk scan that resolved to the given type, e.g.
+// "user", "product" or "unknown".
+func (m *MetricsService) ObserveScan(kind string) {
+	m.scans.WithLabelValues(kind).Inc()
+}
+
+func (m *MetricsService) onTransaction(event events.TransactionCreated) {
+	id := strconv.FormatInt(event.ProductID, 10)
+
+	m.sales.WithLabelValues(id, event.ProductName, event.Origin.Source).Add(float64(event.Quantity))
+	m.revenue.WithLabelValues(id, event.ProductName).Add(event.PricePaid * float64(event.Quantity))
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"gostrecka/internal/utils/static"
+	"gostrecka/services/database"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var stockDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "product_stock"),
+	"Current stock per product.",
+	[]string{"product_id", "product"},
+	nil,
+)
+
+// stockCollector reads the current stock from the database on every
+// scrape, so the gauges never drift from the ledger.
+type stockCollector struct {
+	metrics *MetricsService
+}
+
+func (c *stockCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- stockDesc
+}
+
+func (c *stockCollector) Collect(ch chan<- prometheus.Metric) {
+	db := c.metrics.container.Get(static.DiDatabase).(database.Database)
+
+	products, err := db.SearchProduct("")
+	if err != nil {
+		c.metrics.logger.Error("could not get stock", "error", err)
+		return
+	}
+
+	for _, product := range products {
+		ch <- prometheus.MustNewConstMetric(
+			stockDesc,
+			prometheus.GaugeValue,
+			float64(product.Product.TotalStock),
+			strconv.FormatInt(product.Product.ID, 10),
+			product.Product.Name,
+		)
+	}
+}
@@ -0,0 +1,102 @@
+package products
+
+import (
+	"fmt"
+	"gostrecka/services/database"
+	"gostrecka/services/i18n"
+	"strconv"
+	"strings"
+)
+
+// MaxNameLength is the longest product name accepted, longer names do not
+// fit on the kiosk or the barcode sheets.
+const MaxNameLength = 60
+
+// Form is a product as entered by a user, before it is saved.
+type Form struct {
+	Name          string
+	Category      string
+	PurchasePrice float64
+	InternalPrice float64
+	ExternalPrice float64
+	// Stock is added when the product is created.
+	Stock int64
+	// Upc is the barcode, empty to keep the current one or generate one.
+	Upc string
+}
+
+// FormatPrices writes prices the way ParsePrices reads them.
+func FormatPrices(purchase float64, internal float64, external float64) string {
+	return fmt.Sprintf("%s / %s / %s", formatPrice(purchase), formatPrice(internal), formatPrice(external))
+}
+
+func formatPrice(price float64) string {
+	return strings.ReplaceAll(strconv.FormatFloat(price, 'f', -1, 64), ".", ",")
+}
+
+// ParsePrices reads the purchase, internal and external price separated by
+// slashes. Decimal commas are accepted.
+func ParsePrices(value string) (purchase float64, internal float64, external float64, err error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 3 {
+		return 0, 0, 0, fmt.Errorf("expected 3 prices, got %d", len(parts))
+	}
+
+	prices := make([]float64, 3)
+	for i, part := range parts {
+		part = strings.ReplaceAll(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(part), "kr")), ",", ".")
+		if prices[i], err = strconv.ParseFloat(part, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid price %q", strings.TrimSpace(parts[i]))
+		}
+	}
+
+	return prices[0], prices[1], prices[2], nil
+}
+
+// Validate checks the form before it is saved as product productId, zero
+// for a new product. Every problem found is returned as a message for the
+// user, in locale.
+func (f Form) Validate(db database.Database, productId int64, locale i18n.Locale) (problems []string) {
+	name := strings.TrimSpace(f.Name)
+	switch {
+	case name == "":
+		problems = append(problems, i18n.T(locale, "product.name_missing"))
+	case len([]rune(name)) > MaxNameLength:
+		problems = append(problems, i18n.T(locale, "product.name_too_long", MaxNameLength))
+	default:
+		existing, err := db.SearchProduct(name)
+		if err != nil {
+			problems = append(problems, i18n.T(locale, "product.name_check_failed"))
+		}
+		for _, product := range existing {
+			if product.Product.ID != productId && strings.EqualFold(product.Product.Name, name) {
+				problems = append(problems, i18n.T(locale, "product.name_taken", product.Product.Name))
+				break
+			}
+		}
+	}
+
+	if f.PurchasePrice < 0 || f.InternalPrice < 0 || f.ExternalPrice < 0 {
+		problems = append(problems, i18n.T(locale, "product.negative_price"))
+	}
+	if f.InternalPrice < f.PurchasePrice {
+		problems = append(problems, i18n.T(locale, "product.internal_below_purchase", f.InternalPrice, f.PurchasePrice))
+	}
+	if f.ExternalPrice < f.InternalPrice {
+		problems = append(problems, i18n.T(locale, "product.external_below_internal", f.ExternalPrice, f.InternalPrice))
+	}
+
+	if f.Stock < 0 {
+		problems = append(problems, i18n.T(locale, "product.negative_stock"))
+	}
+
+	if f.Upc != "" {
+		if len(f.Upc) < 8 || len(f.Upc) > 14 || strings.IndexFunc(f.Upc, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+			problems = append(problems, i18n.T(locale, "product.invalid_upc"))
+		} else if lookup, err := db.GetUpcType(f.Upc); err == nil && (lookup.Type != "product" || lookup.ReferableId != strconv.FormatInt(productId, 10)) {
+			problems = append(problems, i18n.T(locale, "product.upc_taken"))
+		}
+	}
+
+	return
+}
@@ -0,0 +1,156 @@
+package products
+
+import (
+	"database/sql"
+	"gostrecka/models"
+	"gostrecka/services/database"
+	"gostrecka/services/i18n"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// stubDatabase answers the lookups Validate makes from fixed data.
+type stubDatabase struct {
+	database.Database
+	products []models.ProductWithPrice
+	upcs     map[string]models.UpcLookup
+}
+
+func (d stubDatabase) SearchProduct(name string) (products []models.ProductWithPrice, err error) {
+	for _, product := range d.products {
+		if strings.Contains(strings.ToLower(product.Product.Name), strings.ToLower(name)) {
+			products = append(products, product)
+		}
+	}
+	return
+}
+
+func (d stubDatabase) GetUpcType(upc string) (models.UpcLookup, error) {
+	lookup, ok := d.upcs[upc]
+	if !ok {
+		return lookup, sql.ErrNoRows
+	}
+	return lookup, nil
+}
+
+func TestParsePrices(t *testing.T) {
+	tests := []struct {
+		value                        string
+		purchase, internal, external float64
+		wantErr                      bool
+	}{
+		{value: "10/12/15", purchase: 10, internal: 12, external: 15},
+		{value: "9,5 / 12,25 / 15", purchase: 9.5, internal: 12.25, external: 15},
+		{value: "10kr/12 kr/ 15 kr ", purchase: 10, internal: 12, external: 15},
+		{value: "9.5/12/15", purchase: 9.5, internal: 12, external: 15},
+		{value: "0/0/0"},
+		{value: "10/12", wantErr: true},
+		{value: "10/12/15/20", wantErr: true},
+		{value: "", wantErr: true},
+		{value: "10/tolv/15", wantErr: true},
+		{value: "10//15", wantErr: true},
+		{value: "1,000,5/2/3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			purchase, internal, external, err := ParsePrices(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParsePrices() = %v, %v, %v, want an error", purchase, internal, external)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if purchase != tt.purchase || internal != tt.internal || external != tt.external {
+				t.Errorf("ParsePrices() = %v, %v, %v, want %v, %v, %v", purchase, internal, external, tt.purchase, tt.internal, tt.external)
+			}
+		})
+	}
+}
+
+func TestFormatPricesRoundTrip(t *testing.T) {
+	purchase, internal, external, err := ParsePrices(FormatPrices(9.5, 12.25, 15))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purchase != 9.5 || internal != 12.25 || external != 15 {
+		t.Errorf("round trip = %v, %v, %v", purchase, internal, external)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	db := stubDatabase{
+		products: []models.ProductWithPrice{
+			{Product: models.Product{ID: 1, Name: "Cola"}},
+			{Product: models.Product{ID: 2, Name: "Cola Zero"}},
+		},
+		upcs: map[string]models.UpcLookup{
+			"73100000": {Type: "product", ReferableId: "1"},
+			"73200000": {Type: "user", ReferableId: "123"},
+		},
+	}
+	valid := Form{Name: "Fanta", PurchasePrice: 8, InternalPrice: 10, ExternalPrice: 15}
+	locale := i18n.English
+
+	tests := []struct {
+		name      string
+		form      func(*Form)
+		productId int64
+		want      []string
+	}{
+		{name: "valid", form: func(*Form) {}},
+		{name: "missing name", form: func(f *Form) { f.Name = "  " }, want: []string{"product.name_missing"}},
+		{name: "long name", form: func(f *Form) { f.Name = strings.Repeat("å", MaxNameLength+1) }, want: []string{"product.name_too_long"}},
+		{name: "taken name", form: func(f *Form) { f.Name = "cola" }, want: []string{"product.name_taken"}},
+		{name: "own name", form: func(f *Form) { f.Name = "Cola" }, productId: 1},
+		{name: "negative price", form: func(f *Form) { f.PurchasePrice = -1 }, want: []string{"product.negative_price"}},
+		{name: "internal below purchase", form: func(f *Form) { f.InternalPrice = 7 }, want: []string{"product.internal_below_purchase"}},
+		{name: "external below internal", form: func(f *Form) { f.ExternalPrice = 9 }, want: []string{"product.external_below_internal"}},
+		{name: "negative stock", form: func(f *Form) { f.Stock = -3 }, want: []string{"product.negative_stock"}},
+		{name: "short upc", form: func(f *Form) { f.Upc = "1234" }, want: []string{"product.invalid_upc"}},
+		{name: "letters in upc", form: func(f *Form) { f.Upc = "7310000A" }, want: []string{"product.invalid_upc"}},
+		{name: "upc of another product", form: func(f *Form) { f.Upc = "73100000" }, want: []string{"product.upc_taken"}},
+		{name: "upc of a user", form: func(f *Form) { f.Upc = "73200000" }, productId: 1, want: []string{"product.upc_taken"}},
+		{name: "own upc", form: func(f *Form) { f.Upc = "73100000" }, productId: 1},
+		{name: "new upc", form: func(f *Form) { f.Upc = "73300000" }},
+		{
+			name: "several problems",
+			form: func(f *Form) { f.Name = ""; f.ExternalPrice = 5; f.Stock = -1 },
+			want: []string{"product.name_missing", "product.external_below_internal", "product.negative_stock"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := valid
+			tt.form(&form)
+
+			problems := form.Validate(db, tt.productId, locale)
+			if len(problems) != len(tt.want) {
+				t.Fatalf("Validate() = %q, want %d problems", problems, len(tt.want))
+			}
+			for i, key := range tt.want {
+				// Compare the message prefix, as some have the values filled in.
+				message, ok := i18n.Lookup(locale, key)
+				if !ok {
+					t.Fatalf("no message for %s", key)
+				}
+				prefix, _, _ := strings.Cut(message, "%")
+				if !strings.HasPrefix(problems[i], prefix) {
+					t.Errorf("problem %d = %q, want %s", i, problems[i], key)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateLocale(t *testing.T) {
+	problems := Form{}.Validate(stubDatabase{}, 0, i18n.Swedish)
+	if !slices.Contains(problems, i18n.T(i18n.Swedish, "product.name_missing")) {
+		t.Errorf("Validate() = %q, want the Swedish message", problems)
+	}
+}
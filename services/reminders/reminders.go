@@ -0,0 +1,244 @@
+package reminders
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gostrecka/internal/utils/static"
+	"gostrecka/models"
+	"gostrecka/services/database"
+	"gostrecka/services/env"
+	"gostrecka/services/i18n"
+	"gostrecka/utils"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/sarulabs/di/v2"
+)
+
+type ReminderService struct {
+	container di.Container
+	logger    *slog.Logger
+	config    env.RemindersConfig
+}
+
+// Result summarises a reminder run.
+type Result struct {
+	Reminded []models.UserWithBalance
+	Failed   []models.UserWithBalance
+	// Errors are why the run stopped early for a guild, by guild id, with
+	// the default database under "".
+	Errors map[string]error
+}
+
+func New(container di.Container) *ReminderService {
+	return &ReminderService{
+		container: container,
+		logger:    container.Get("logger").(*slog.Logger).With("service", "REMINDERS"),
+		config:    container.Get(static.DiConfig).(env.Config).Reminders,
+	}
+}
+
+// Cooldown returns the least time between two reminders to the same user.
+func (s *ReminderService) Cooldown() time.Duration {
+	if s.config.Cooldown == "" || s.config.Cooldown == "0" {
+		return 0
+	}
+
+	cooldown, err := time.ParseDuration(s.config.Cooldown)
+	if err != nil {
+		s.logger.Error("invalid reminder cooldown", "cooldown", s.config.Cooldown, "error", err)
+		return 0
+	}
+
+	return cooldown
+}
+
+// Run reminds every user whose debt exceeds the threshold, in the default
+// database and in that of every guild with one of its own, unless they
+// have opted out or were reminded within the cooldown. A summary of each
+// database is posted to the admin channel of its guild, so no association
+// sees the debts of another's members.
+func (s *ReminderService) Run() (result Result, err error) {
+	guilds := s.container.Get(static.DiGuilds).(*database.Guilds)
+	session := s.container.Get(static.DiDiscordSession).(*discordgo.Session)
+
+	// A guild whose database fails does not stop the others, and the
+	// reminders it already sent are still summarised.
+	var errs []error
+	for _, guildId := range append([]string{""}, guilds.IDs()...) {
+		var guildResult Result
+		if err := s.run(guildId, guilds.Get(guildId), session, &guildResult); err != nil {
+			s.logger.Error("Could not send debt reminders", "guild", guildId, "error", err)
+			guildResult.Errors = map[string]error{guildId: err}
+			if result.Errors == nil {
+				result.Errors = map[string]error{}
+			}
+			result.Errors[guildId] = err
+			errs = append(errs, fmt.Errorf("guild %q: %w", guildId, err))
+		}
+
+		s.summarise(session, guildId, guildResult)
+		result.Reminded = append(result.Reminded, guildResult.Reminded...)
+		result.Failed = append(result.Failed, guildResult.Failed...)
+	}
+
+	s.logger.Info("Debt reminders sent", "reminded", len(result.Reminded), "failed", len(result.Failed))
+
+	return result, errors.Join(errs...)
+}
+
+// run reminds the users of the database of a guild, adding them to result.
+func (s *ReminderService) run(guildId string, db database.Database, session *discordgo.Session, result *Result) error {
+	config := s.container.Get(static.DiConfig).(env.Config)
+	swish := config.SwishOf(guildId)
+
+	users, err := db.GetUsers()
+	if err != nil {
+		return err
+	}
+
+	cooldown := s.Cooldown()
+	for _, user := range users {
+		if user.Balance.DebtIncurred <= s.config.Threshold {
+			continue
+		}
+
+		settings, err := db.GetUserSettings(user.User.ID)
+		if err != nil {
+			return err
+		}
+
+		if !settings.DebtReminders {
+			continue
+		}
+		if !settings.LastRemindedAt.IsZero() && time.Since(settings.LastRemindedAt) < cooldown {
+			continue
+		}
+
+		locale := i18n.Resolve(settings.Locale, config.Locale)
+		if err := s.remind(session, user, locale, swish); err != nil {
+			s.logger.Warn("Could not send debt reminder", "user", user.User.ID, "error", err)
+			result.Failed = append(result.Failed, user)
+			continue
+		}
+
+		if err := db.MarkReminded(user.User.ID, time.Now()); err != nil {
+			return err
+		}
+
+		result.Reminded = append(result.Reminded, user)
+	}
+
+	return nil
+}
+
+func (s *ReminderService) remind(session *discordgo.Session, user models.UserWithBalance, locale i18n.Locale, swish env.SwishConfig) error {
+	channel, err := session.UserChannelCreate(user.User.ID)
+	if err != nil {
+		return err
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       i18n.T(locale, "reminder.title"),
+		Description: i18n.T(locale, "reminder.description", user.User.Name, user.Balance.DebtIncurred),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: i18n.T(locale, "reminder.footer"),
+		},
+	}
+
+	message := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+
+	if swish.Enabled() {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  i18n.T(locale, "balance.swish_title"),
+			Value: i18n.T(locale, "reminder.swish", swish.Payee, swish.Reference(user.User.ID)),
+		})
+
+		qr, err := utils.GenerateSwishQR(swish.Payee, user.Balance.DebtIncurred, swish.Reference(user.User.ID), 256)
+		if err != nil {
+			s.logger.Error("Could not generate Swish QR code", "error", err)
+		} else {
+			embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://swish.png"}
+			message.Files = []*discordgo.File{
+				{
+					Name:        "swish.png",
+					ContentType: "image/png",
+					Reader:      bytes.NewReader(qr),
+				},
+			}
+		}
+	} else {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  i18n.T(locale, "reminder.pay_title"),
+			Value: i18n.T(locale, "reminder.pay"),
+		})
+	}
+
+	_, err = session.ChannelMessageSendComplex(channel.ID, message)
+	return err
+}
+
+func (s *ReminderService) summarise(session *discordgo.Session, guildId string, result Result) {
+	config := s.container.Get(static.DiConfig).(env.Config)
+	channel := config.ReminderChannel(guildId)
+	err := result.Errors[guildId]
+	if channel == "" || (len(result.Reminded)+len(result.Failed) == 0 && err == nil) {
+		return
+	}
+
+	locale := i18n.Resolve(config.Locale)
+	embed := &discordgo.MessageEmbed{
+		Title:       i18n.T(locale, "reminder.summary_title"),
+		Description: i18n.T(locale, "reminder.summary", len(result.Reminded), len(result.Failed)),
+	}
+
+	if len(result.Reminded) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  i18n.T(locale, "reminder.summary_reminded"),
+			Value: summaryLines(locale, result.Reminded),
+		})
+	}
+	if len(result.Failed) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  i18n.T(locale, "reminder.summary_failed"),
+			Value: summaryLines(locale, result.Failed),
+		})
+	}
+	if err != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  i18n.T(locale, "reminder.summary_error"),
+			Value: err.Error(),
+		})
+	}
+
+	if _, err := session.ChannelMessageSendEmbed(channel, embed); err != nil {
+		s.logger.Error("Could not post reminder summary", "channel", channel, "error", err)
+	}
+}
+
+// summaryLines lists users and their debt, cut to fit in an embed field.
+func summaryLines(locale i18n.Locale, users []models.UserWithBalance) string {
+	var lines []string
+	for _, user := range users {
+		lines = append(lines, i18n.T(locale, "reminder.summary_line", user.User.ID, user.Balance.DebtIncurred))
+	}
+
+	value := strings.Join(lines, "\n")
+	if len(value) > 1024 {
+		cut := strings.LastIndex(value[:1024], "\n")
+		if cut < 0 {
+			// The first line is too long on its own, cut it between runes.
+			cut = 1024
+			for !utf8.RuneStart(value[cut]) {
+				cut--
+			}
+		}
+		value = value[:cut]
+	}
+
+	return value
+}
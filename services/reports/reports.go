@@ -0,0 +1,156 @@
+package reports
+
+import (
+	"errors"
+	"gostrecka/internal/utils/static"
+	"gostrecka/models"
+	"gostrecka/services/database"
+	"gostrecka/services/env"
+	"gostrecka/services/i18n"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sarulabs/di/v2"
+)
+
+const (
+	GroupProduct  = "product"
+	GroupCategory = "category"
+	GroupDay      = "day"
+	GroupMonth    = "month"
+)
+
+var ErrUnknownGrouping = errors.New("unknown report grouping")
+
+// Line is the sales of one product, category or period.
+type Line struct {
+	Key      string  `json:"key"`
+	Label    string  `json:"label"`
+	Quantity int64   `json:"quantity"`
+	Revenue  float64 `json:"revenue"`
+	Cost     float64 `json:"cost"`
+	Margin   float64 `json:"margin"`
+	// MarginPercent is the margin as a share of the revenue, zero when
+	// nothing was earned.
+	MarginPercent float64 `json:"margin_percent"`
+}
+
+// Report summarises the sales in the period [From, To).
+type Report struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	GroupBy string    `json:"group_by"`
+	Lines   []Line    `json:"lines"`
+	Total   Line      `json:"total"`
+}
+
+// Generate builds a report of the sales in the period [from, to), grouped
+// by product, category, day or month and labelled in locale.
+func Generate(db database.Database, from time.Time, to time.Time, groupBy string, locale i18n.Locale) (report Report, err error) {
+	sales, err := db.GetSales(from, to)
+	if err != nil {
+		return
+	}
+
+	return Build(sales, from, to, groupBy, locale)
+}
+
+// Build groups sales into a report labelled in locale. Products and
+// categories are ordered by revenue, days and months by date.
+func Build(sales []models.ProductSales, from time.Time, to time.Time, groupBy string, locale i18n.Locale) (Report, error) {
+	key, ok := groupings[groupBy]
+	if !ok {
+		return Report{}, ErrUnknownGrouping
+	}
+
+	report := Report{From: from, To: to, GroupBy: groupBy, Lines: []Line{}, Total: Line{Key: "total", Label: i18n.T(locale, "report.total")}}
+
+	lines := make(map[string]*Line)
+	var order []string
+	for _, sale := range sales {
+		k, label := key(sale)
+		if k == "" && groupBy == GroupCategory {
+			label = i18n.T(locale, "product.uncategorised")
+		}
+
+		line := lines[k]
+		if line == nil {
+			line = &Line{Key: k, Label: label}
+			lines[k] = line
+			order = append(order, k)
+		}
+
+		line.add(sale)
+		report.Total.add(sale)
+	}
+
+	for _, k := range order {
+		lines[k].finish()
+		report.Lines = append(report.Lines, *lines[k])
+	}
+	report.Total.finish()
+
+	if groupBy == GroupProduct || groupBy == GroupCategory {
+		sort.SliceStable(report.Lines, func(i, j int) bool {
+			return report.Lines[i].Revenue > report.Lines[j].Revenue
+		})
+	}
+
+	return report, nil
+}
+
+var groupings = map[string]func(models.ProductSales) (key string, label string){
+	GroupProduct: func(s models.ProductSales) (string, string) {
+		return strconv.FormatInt(s.ProductID, 10), s.ProductName
+	},
+	GroupCategory: func(s models.ProductSales) (string, string) {
+		return s.Category, s.Category
+	},
+	GroupDay: func(s models.ProductSales) (string, string) {
+		day := s.Date.Format("2006-01-02")
+		return day, day
+	},
+	GroupMonth: func(s models.ProductSales) (string, string) {
+		month := s.Date.Format("2006-01")
+		return month, month
+	},
+}
+
+func (l *Line) add(sale models.ProductSales) {
+	l.Quantity += sale.Quantity
+	l.Revenue += sale.Revenue
+	l.Cost += sale.Cost
+}
+
+func (l *Line) finish() {
+	l.Margin = l.Revenue - l.Cost
+	if l.Revenue != 0 {
+		l.MarginPercent = l.Margin / l.Revenue * 100
+	}
+}
+
+type ReportService struct {
+	container di.Container
+}
+
+func New(container di.Container) *ReportService {
+	return &ReportService{
+		container: container,
+	}
+}
+
+// GetReport returns the sales in the period [from, to) for the admin view.
+func (r *ReportService) GetReport(from time.Time, to time.Time, groupBy string) Report {
+	db := r.container.Get(static.DiDatabase).(database.Database)
+	locale := i18n.Resolve(r.container.Get(static.DiConfig).(env.Config).Locale)
+	report, err := Generate(db, from, to, groupBy, locale)
+
+	if err != nil {
+		log.Printf("error getting report: %v", err)
+		return Report{From: from, To: to, GroupBy: groupBy, Lines: []Line{}}
+	}
+
+	return report
+}
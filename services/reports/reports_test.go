@@ -0,0 +1,98 @@
+package reports
+
+import (
+	"errors"
+	"gostrecka/models"
+	"gostrecka/services/i18n"
+	"testing"
+	"time"
+)
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2026, month, d, 0, 0, 0, 0, time.Local)
+}
+
+// percent computes a margin percentage the way Build does, as constant
+// expressions are exact and would not match its rounding.
+func percent(margin float64, revenue float64) float64 {
+	return margin / revenue * 100
+}
+
+var sales = []models.ProductSales{
+	{Date: day(time.January, 31), ProductID: 1, ProductName: "Cola", Category: "Läsk", Quantity: 4, Revenue: 40, Cost: 20},
+	{Date: day(time.February, 1), ProductID: 2, ProductName: "Fanta", Category: "Läsk", Quantity: 1, Revenue: 10, Cost: 6},
+	{Date: day(time.January, 31), ProductID: 3, ProductName: "Kaffe", Quantity: 10, Revenue: 50, Cost: 10},
+	{Date: day(time.February, 1), ProductID: 1, ProductName: "Cola", Category: "Läsk", Quantity: 2, Revenue: 20, Cost: 10},
+	{Date: day(time.February, 2), ProductID: 4, ProductName: "Gåva", Quantity: 3, Revenue: 0, Cost: 9},
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		groupBy string
+		locale  i18n.Locale
+		want    []Line
+	}{
+		{GroupProduct, i18n.Swedish, []Line{
+			{Key: "1", Label: "Cola", Quantity: 6, Revenue: 60, Cost: 30, Margin: 30, MarginPercent: 50},
+			{Key: "3", Label: "Kaffe", Quantity: 10, Revenue: 50, Cost: 10, Margin: 40, MarginPercent: 80},
+			{Key: "2", Label: "Fanta", Quantity: 1, Revenue: 10, Cost: 6, Margin: 4, MarginPercent: 40},
+			{Key: "4", Label: "Gåva", Quantity: 3, Revenue: 0, Cost: 9, Margin: -9, MarginPercent: 0},
+		}},
+		{GroupCategory, i18n.English, []Line{
+			{Key: "Läsk", Label: "Läsk", Quantity: 7, Revenue: 70, Cost: 36, Margin: 34, MarginPercent: percent(34, 70)},
+			{Key: "", Label: "Uncategorised", Quantity: 13, Revenue: 50, Cost: 19, Margin: 31, MarginPercent: 62},
+		}},
+		{GroupDay, i18n.Swedish, []Line{
+			{Key: "2026-01-31", Label: "2026-01-31", Quantity: 14, Revenue: 90, Cost: 30, Margin: 60, MarginPercent: percent(60, 90)},
+			{Key: "2026-02-01", Label: "2026-02-01", Quantity: 3, Revenue: 30, Cost: 16, Margin: 14, MarginPercent: percent(14, 30)},
+			{Key: "2026-02-02", Label: "2026-02-02", Quantity: 3, Revenue: 0, Cost: 9, Margin: -9, MarginPercent: 0},
+		}},
+		{GroupMonth, i18n.Swedish, []Line{
+			{Key: "2026-01", Label: "2026-01", Quantity: 14, Revenue: 90, Cost: 30, Margin: 60, MarginPercent: percent(60, 90)},
+			{Key: "2026-02", Label: "2026-02", Quantity: 6, Revenue: 30, Cost: 25, Margin: 5, MarginPercent: percent(5, 30)},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.groupBy, func(t *testing.T) {
+			report, err := Build(sales, day(time.January, 1), day(time.March, 1), tt.groupBy, tt.locale)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(report.Lines) != len(tt.want) {
+				t.Fatalf("Build() lines = %+v, want %+v", report.Lines, tt.want)
+			}
+			for i, line := range report.Lines {
+				if line != tt.want[i] {
+					t.Errorf("line %d = %+v, want %+v", i, line, tt.want[i])
+				}
+			}
+
+			total := Line{Key: "total", Label: i18n.T(tt.locale, "report.total"), Quantity: 20, Revenue: 120, Cost: 55, Margin: 65, MarginPercent: percent(65, 120)}
+			if report.Total != total {
+				t.Errorf("total = %+v, want %+v", report.Total, total)
+			}
+		})
+	}
+}
+
+func TestBuildEmpty(t *testing.T) {
+	report, err := Build(nil, day(time.January, 1), day(time.February, 1), GroupProduct, i18n.English)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Lines == nil || len(report.Lines) != 0 {
+		t.Errorf("lines = %#v, want an empty slice", report.Lines)
+	}
+	if report.Total.MarginPercent != 0 || report.Total.Label != "Total" {
+		t.Errorf("total = %+v, want zero with the English label", report.Total)
+	}
+}
+
+func TestBuildUnknownGrouping(t *testing.T) {
+	if _, err := Build(sales, day(time.January, 1), day(time.March, 1), "week", i18n.Swedish); !errors.Is(err, ErrUnknownGrouping) {
+		t.Errorf("Build() error = %v, want ErrUnknownGrouping", err)
+	}
+}
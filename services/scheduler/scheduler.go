@@ -0,0 +1,268 @@
+package scheduler
+
+import (
+	"errors"
+	"gostrecka/internal/utils/static"
+	"gostrecka/models"
+	"gostrecka/services/database"
+	"gostrecka/services/env"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sarulabs/di/v2"
+)
+
+// tickInterval is how often the scheduler looks for due jobs. Cron
+// schedules have minute resolution, so this only needs to be shorter.
+const tickInterval = 30 * time.Second
+
+var (
+	ErrUnknownJob = errors.New("unknown job")
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// Job is a unit of background work that can be scheduled or triggered.
+type Job struct {
+	Name        string
+	Description string
+	Run         func() error
+}
+
+// JobStatus describes a registered job. Next is zero for jobs without a
+// schedule and LastRun is zero for jobs that have never run.
+type JobStatus struct {
+	Name        string
+	Description string
+	Schedule    string
+	Next        time.Time
+	LastRun     models.JobRun
+	Running     bool
+}
+
+type job struct {
+	Job
+	spec     string
+	schedule cron.Schedule
+	running  bool
+}
+
+type SchedulerService struct {
+	container di.Container
+	logger    *slog.Logger
+	config    map[string]string
+
+	mu      sync.Mutex
+	jobs    []*job
+	started time.Time
+}
+
+func New(container di.Container) *SchedulerService {
+	return &SchedulerService{
+		container: container,
+		logger:    container.Get("logger").(*slog.Logger).With("service", "SCHEDULER"),
+		config:    container.Get(static.DiConfig).(env.Config).Jobs,
+		started:   time.Now(),
+	}
+}
+
+// Register adds a job, scheduled according to the config. It must be
+// called before Start.
+func (s *SchedulerService) Register(j Job) {
+	registered := &job{Job: j, spec: s.config[j.Name]}
+
+	if registered.spec != "" {
+		schedule, err := cron.ParseStandard(registered.spec)
+		if err != nil {
+			s.logger.Error("Invalid job schedule, job will only run when triggered", "job", j.Name, "schedule", registered.spec, "error", err)
+			registered.spec = ""
+		} else {
+			registered.schedule = schedule
+		}
+	}
+
+	s.mu.Lock()
+	s.jobs = append(s.jobs, registered)
+	s.mu.Unlock()
+}
+
+// Start runs due jobs until the process exits. A job is due when its
+// schedule has passed since its last recorded run, so a run missed while
+// the process was down happens once on startup and a restart never repeats
+// a run.
+func (s *SchedulerService) Start() {
+	s.mu.Lock()
+	s.started = time.Now()
+	for _, j := range s.jobs {
+		if j.schedule != nil {
+			s.logger.Info("Job scheduled", "job", j.Name, "schedule", j.spec)
+		}
+	}
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		s.tick()
+		<-ticker.C
+	}
+}
+
+func (s *SchedulerService) tick() {
+	runs, err := s.lastRuns()
+	if err != nil {
+		s.logger.Error("Could not read job runs", "error", err)
+		return
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.schedule == nil || j.running {
+			continue
+		}
+
+		if now.Before(s.next(j, runs[j.Name])) {
+			continue
+		}
+
+		j.running = true
+		go s.run(j)
+	}
+}
+
+// next returns when j is due after its last run. Jobs that have never run
+// are due at their first scheduled time after startup.
+func (s *SchedulerService) next(j *job, last models.JobRun) time.Time {
+	since := last.StartedAt
+	if since.IsZero() {
+		since = s.started
+	}
+
+	return j.schedule.Next(since.In(time.Local))
+}
+
+// Trigger runs the named job right away and waits for it to finish.
+func (s *SchedulerService) Trigger(name string) error {
+	s.mu.Lock()
+	var found *job
+	for _, j := range s.jobs {
+		if j.Name == name {
+			found = j
+		}
+	}
+
+	if found == nil {
+		s.mu.Unlock()
+		return ErrUnknownJob
+	}
+	if found.running {
+		s.mu.Unlock()
+		return ErrJobRunning
+	}
+
+	found.running = true
+	s.mu.Unlock()
+
+	return s.run(found)
+}
+
+// run executes j, which must already be marked as running, and records the
+// outcome.
+func (s *SchedulerService) run(j *job) error {
+	defer func() {
+		s.mu.Lock()
+		j.running = false
+		s.mu.Unlock()
+	}()
+
+	db := s.container.Get(static.DiDatabase).(database.Database)
+
+	// The start is recorded first so a crash during the run does not cause
+	// the job to run again on the next start.
+	run := models.JobRun{Name: j.Name, StartedAt: time.Now(), Status: models.JobRunning}
+	if err := db.SaveJobRun(run); err != nil {
+		s.logger.Error("Could not record job start", "job", j.Name, "error", err)
+	}
+
+	s.logger.Info("Running job", "job", j.Name)
+	err := s.safeRun(j)
+
+	run.FinishedAt = time.Now()
+	run.Status = models.JobOk
+	if err != nil {
+		run.Status = models.JobFailed
+		run.Error = err.Error()
+		s.logger.Error("Job failed", "job", j.Name, "error", err)
+	} else {
+		s.logger.Info("Job finished", "job", j.Name, "duration", run.FinishedAt.Sub(run.StartedAt))
+	}
+
+	if err := db.SaveJobRun(run); err != nil {
+		s.logger.Error("Could not record job run", "job", j.Name, "error", err)
+	}
+
+	return err
+}
+
+func (s *SchedulerService) safeRun(j *job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Job panicked", "job", j.Name, "panic", r)
+			err = errors.New("job panicked")
+		}
+	}()
+
+	return j.Run()
+}
+
+// Jobs returns the status of every registered job in registration order.
+func (s *SchedulerService) Jobs() ([]JobStatus, error) {
+	runs, err := s.lastRuns()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		status := JobStatus{
+			Name:        j.Name,
+			Description: j.Description,
+			Schedule:    j.spec,
+			LastRun:     runs[j.Name],
+			Running:     j.running,
+		}
+
+		if j.schedule != nil {
+			status.Next = s.next(j, runs[j.Name])
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func (s *SchedulerService) lastRuns() (map[string]models.JobRun, error) {
+	db := s.container.Get(static.DiDatabase).(database.Database)
+
+	runs, err := db.GetJobRuns()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]models.JobRun, len(runs))
+	for _, run := range runs {
+		byName[run.Name] = run
+	}
+
+	return byName, nil
+}
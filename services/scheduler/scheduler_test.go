@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"gostrecka/models"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+func TestNext(t *testing.T) {
+	schedule, err := cron.ParseStandard("0 18 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := &job{Job: Job{Name: "test"}, spec: "0 18 * * *", schedule: schedule}
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.October, day, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name    string
+		started time.Time
+		last    time.Time
+		want    time.Time
+	}{
+		{"never run, before the time", at(19, 12, 0), time.Time{}, at(19, 18, 0)},
+		{"never run, after the time", at(19, 20, 0), time.Time{}, at(20, 18, 0)},
+		{"restart after a run", at(19, 18, 10), at(19, 18, 0), at(20, 18, 0)},
+		{"run missed while down", at(20, 20, 0), at(18, 18, 0), at(19, 18, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SchedulerService{started: tt.started}
+
+			got := s.next(j, models.JobRun{Name: j.Name, StartedAt: tt.last})
+			if !got.Equal(tt.want) {
+				t.Errorf("next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// A restart right after a run must not run the job again, and a run missed
+// while the process was down happens once, not once per missed time.
+func TestNextDoesNotRepeatRuns(t *testing.T) {
+	schedule, err := cron.ParseStandard("@every 6h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := &job{Job: Job{Name: "backup"}, spec: "@every 6h", schedule: schedule}
+
+	lastRun := time.Date(2026, time.October, 19, 6, 0, 0, 0, time.Local)
+	restart := lastRun.Add(time.Minute)
+	s := &SchedulerService{started: restart}
+
+	if next := s.next(j, models.JobRun{StartedAt: lastRun}); !restart.Before(next) {
+		t.Errorf("job due again at %v right after running at %v", next, lastRun)
+	}
+
+	// Down for a day, the job is due once and then not until six hours
+	// after that run.
+	now := lastRun.Add(24 * time.Hour)
+	s.started = now
+	if next := s.next(j, models.JobRun{StartedAt: lastRun}); now.Before(next) {
+		t.Errorf("missed job not due, next at %v", next)
+	}
+	if next := s.next(j, models.JobRun{StartedAt: now}); !next.Equal(now.Add(6 * time.Hour)) {
+		t.Errorf("next() = %v after catching up, want %v", next, now.Add(6*time.Hour))
+	}
+}
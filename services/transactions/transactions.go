@@ -1,13 +1,19 @@
 package transactions
 
 import (
+	"encoding/base64"
+	"gostrecka/internal/utils/static"
 	"gostrecka/models"
 	"gostrecka/services/database"
+	"gostrecka/services/env"
+	"gostrecka/services/i18n"
+	"gostrecka/services/leaderboard"
+	"gostrecka/services/metrics"
+	"gostrecka/utils"
 	"log"
 	"strconv"
 
 	"github.com/sarulabs/di/v2"
-	"github.com/wailsapp/wails/v3/pkg/application"
 )
 
 type TransactionService struct {
@@ -20,37 +26,47 @@ func New(container di.Container) *TransactionService {
 	}
 }
 
+// GetLatestTransactions returns the transactions behind the kiosk's
+// leaderboard for the chart.
 func (a *TransactionService) GetLatestTransactions() []models.LatestTransaction {
 	db := a.container.Get("database").(database.Database)
-	transactions, err := db.GetLatestTransactions()
+	config := a.container.Get(static.DiConfig).(env.Config).Leaderboard
+	transactions, err := leaderboard.Latest(db, config, leaderboard.Default(config))
 
 	if err != nil {
+		log.Printf("error getting latest transactions: %v", err)
 		return []models.LatestTransaction{}
 	}
 
 	return transactions
 }
 
+// GetLeaderboard returns the leaderboard configured for the kiosk.
 func (a *TransactionService) GetLeaderboard() []models.TransactionLeaderboard {
 	db := a.container.Get("database").(database.Database)
-	leaderboard, err := db.GetTransactionLeaderboard()
+	config := a.container.Get(static.DiConfig).(env.Config).Leaderboard
+	standings, err := leaderboard.Get(db, config, leaderboard.Default(config))
 
 	if err != nil {
+		log.Printf("error getting leaderboard: %v", err)
 		return []models.TransactionLeaderboard{}
 	}
 
-	return leaderboard
+	return standings
 }
 
 func (a *TransactionService) ScanUpc(upc string) interface{} {
 
 	db := a.container.Get("database").(database.Database)
+	scans := a.container.Get(static.DiMetrics).(*metrics.MetricsService)
 	log.Printf("scanning upc: %v", upc)
 	result, err := db.GetUpcType(upc)
 	if err != nil {
 		log.Printf("error getting upc type: %v", err)
+		scans.ObserveScan("unknown")
 		return nil
 	}
+	scans.ObserveScan(result.Type)
 
 	if result.Type == "product" {
 		id, _ := strconv.ParseInt(result.ReferableId, 10, 64)
@@ -87,12 +103,13 @@ func (a *TransactionService) ScanUpc(upc string) interface{} {
 
 func (a *TransactionService) Strecka(ProductID int64, UserID string, amount int64) (result interface{}) {
 	db := a.container.Get("database").(database.Database)
-	err := db.Strecka(models.User{ID: UserID}, ProductID, amount)
+	locale := a.locale(db, UserID)
+	_, err := db.Strecka(models.Origin{Source: models.OriginKiosk, ActorID: UserID}, models.User{ID: UserID}, ProductID, amount)
 
 	if err != nil {
 		log.Printf("error strecka: %v", err)
 		return map[string]interface{}{
-			"error":   err.Error(),
+			"error":   i18n.T(locale, "strecka.failed"),
 			"user":    nil,
 			"product": nil,
 			"balance": nil,
@@ -103,7 +120,7 @@ func (a *TransactionService) Strecka(ProductID int64, UserID string, amount int6
 	if err != nil {
 		log.Printf("error getting user: %v", err)
 		return map[string]interface{}{
-			"error":   err.Error(),
+			"error":   i18n.T(locale, "error.user_not_found"),
 			"user":    nil,
 			"product": nil,
 			"balance": nil,
@@ -114,7 +131,7 @@ func (a *TransactionService) Strecka(ProductID int64, UserID string, amount int6
 	if err != nil {
 		log.Printf("error getting product: %v", err)
 		return map[string]interface{}{
-			"error":   err.Error(),
+			"error":   i18n.T(locale, "error.product_not_found"),
 			"user":    nil,
 			"product": nil,
 			"balance": nil,
@@ -128,8 +145,45 @@ func (a *TransactionService) Strecka(ProductID int64, UserID string, amount int6
 		"balance": balance,
 	}
 
-	app := a.container.Get("app").(*application.App)
-	app.Events.Emit(&application.WailsEvent{Name: "transaction_updated", Sender: "App"})
-
 	return
 }
+
+// locale returns the language the kiosk answers the user in, the one they
+// have picked with /settings or otherwise the configured one.
+func (a *TransactionService) locale(db database.Database, userId string) i18n.Locale {
+	settings, err := db.GetUserSettings(userId)
+	if err != nil {
+		log.Printf("error getting settings: %v", err)
+	}
+
+	return i18n.Resolve(settings.Locale, a.container.Get(static.DiConfig).(env.Config).Locale)
+}
+
+// GetSwishQR returns a Swish QR code for paying off the user's debt as a
+// PNG data URL, or an empty string when the user has no debt or Swish is
+// not configured.
+func (a *TransactionService) GetSwishQR(UserID string) string {
+	swish := a.container.Get(static.DiConfig).(env.Config).Swish
+	if !swish.Enabled() {
+		return ""
+	}
+
+	db := a.container.Get("database").(database.Database)
+	_, balance, err := db.GetUser(UserID)
+	if err != nil {
+		log.Printf("error getting user: %v", err)
+		return ""
+	}
+
+	if balance.DebtIncurred <= 0 {
+		return ""
+	}
+
+	qr, err := utils.GenerateSwishQR(swish.Payee, balance.DebtIncurred, swish.Reference(UserID), 256)
+	if err != nil {
+		log.Printf("error generating swish qr: %v", err)
+		return ""
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(qr)
+}
@@ -0,0 +1,321 @@
+package utils
+
+import (
+	"fmt"
+	"gostrecka/models"
+	"gostrecka/services/i18n"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	chartWidth   = 1200
+	chartHeight  = 600
+	chartPadding = 24
+	// rankingWidth is the width of the ranking panel to the left of the
+	// chart.
+	rankingWidth = 380
+	rankingRows  = 10
+)
+
+var (
+	chartBackground = color.RGBA{27, 38, 54, 255}
+	chartForeground = color.RGBA{230, 233, 237, 255}
+	chartMuted      = color.RGBA{140, 150, 165, 255}
+	chartGrid       = color.RGBA{48, 61, 80, 255}
+	chartUp         = color.RGBA{74, 200, 120, 255}
+	chartDown       = color.RGBA{230, 90, 90, 255}
+)
+
+// LeaderboardChart is the data behind a rendered leaderboard. Standings are
+// ordered by rank and transactions carry each user's running totals, oldest
+// first.
+type LeaderboardChart struct {
+	Title        string
+	Metric       string
+	From         time.Time
+	To           time.Time
+	Standings    []models.TransactionLeaderboard
+	Transactions []models.LatestTransaction
+	// Locale is the language of the labels drawn in the chart.
+	Locale i18n.Locale
+}
+
+// GenerateLeaderboardPNG writes the top of the leaderboard next to a chart
+// of how each of those users' totals grew over the period to w.
+func GenerateLeaderboardPNG(chart LeaderboardChart, w io.Writer) error {
+	regular, err := loadFace("assets/Roboto-Regular.ttf", 18)
+	if err != nil {
+		return err
+	}
+	defer regular.Close()
+
+	small, err := loadFace("assets/Roboto-Regular.ttf", 14)
+	if err != nil {
+		return err
+	}
+	defer small.Close()
+
+	bold, err := loadFace("assets/Roboto-Bold.ttf", 26)
+	if err != nil {
+		return err
+	}
+	defer bold.Close()
+
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(chartBackground), image.Point{}, draw.Src)
+
+	drawText(img, bold, chartForeground, chartPadding, chartPadding+26, chart.Title)
+
+	standings := chart.Standings
+	if len(standings) > rankingRows {
+		standings = standings[:rankingRows]
+	}
+
+	colors := make(map[string]color.RGBA, len(standings))
+	for i, standing := range standings {
+		colors[standing.UserID] = userColor(i, len(standings))
+	}
+
+	drawRanking(img, regular, standings, colors, chart)
+
+	plot := image.Rect(rankingWidth+chartPadding+60, chartPadding+60, chartWidth-chartPadding, chartHeight-chartPadding-30)
+	drawChart(img, small, plot, chart, colors)
+
+	if len(standings) == 0 {
+		drawText(img, regular, chartMuted, chartPadding, chartPadding+80, i18n.T(chart.Locale, "leaderboard.empty"))
+	}
+
+	return png.Encode(w, img)
+}
+
+func drawRanking(img *image.RGBA, face font.Face, standings []models.TransactionLeaderboard, colors map[string]color.RGBA, chart LeaderboardChart) {
+	y := chartPadding + 80
+	for _, standing := range standings {
+		c := colors[standing.UserID]
+		draw.Draw(img, image.Rect(chartPadding, y-14, chartPadding+6, y+4), image.NewUniform(c), image.Point{}, draw.Src)
+
+		drawText(img, face, chartForeground, chartPadding+16, y, fmt.Sprintf("%d.", standing.CurrentRank))
+		drawText(img, face, chartForeground, chartPadding+50, y, truncateText(face, standing.UserName, 170))
+
+		score := formatScore(chart.Locale, standing.Score, chart.Metric)
+		drawText(img, face, chartForeground, rankingWidth-80-textWidth(face, score), y, score)
+
+		change, changeColor := rankChange(chart.Locale, standing)
+		drawText(img, face, changeColor, rankingWidth-60, y, change)
+
+		y += 46
+	}
+}
+
+func drawChart(img *image.RGBA, face font.Face, plot image.Rectangle, chart LeaderboardChart, colors map[string]color.RGBA) {
+	from, to := chart.From, chart.To
+	if from.IsZero() && len(chart.Transactions) > 0 {
+		from = chart.Transactions[0].TransactionDate
+	}
+	if !to.After(from) {
+		from = to.Add(-time.Hour)
+	}
+
+	// Only the ranked users are drawn, everyone else would be an
+	// unlabelled line.
+	points := make(map[string][]chartPoint)
+	var max float64
+	for _, transaction := range chart.Transactions {
+		if _, ok := colors[transaction.UserID]; !ok {
+			continue
+		}
+
+		value := float64(transaction.CumulativeTransactionCount)
+		if chart.Metric == models.LeaderboardSpend {
+			value = transaction.CumulativeSpend
+		}
+
+		points[transaction.UserID] = append(points[transaction.UserID], chartPoint{transaction.TransactionDate, value})
+		max = math.Max(max, value)
+	}
+
+	step := niceStep(max / 5)
+	top := math.Max(step, math.Ceil(max/step)*step)
+
+	x := func(t time.Time) int {
+		share := float64(t.Sub(from)) / float64(to.Sub(from))
+		return plot.Min.X + int(math.Round(share*float64(plot.Dx())))
+	}
+	y := func(value float64) int {
+		return plot.Max.Y - int(math.Round(value/top*float64(plot.Dy())))
+	}
+
+	for value := 0.0; value <= top+step/2; value += step {
+		fillRect(img, plot.Min.X, y(value), plot.Max.X, y(value)+1, chartGrid)
+
+		label := formatScore(chart.Locale, value, chart.Metric)
+		drawText(img, face, chartMuted, plot.Min.X-10-textWidth(face, label), y(value)+5, label)
+	}
+
+	layout := "15:04"
+	if to.Sub(from) > 36*time.Hour {
+		layout = "2/1"
+	}
+	for i := 0; i <= 4; i++ {
+		t := from.Add(to.Sub(from) * time.Duration(i) / 4)
+		label := t.In(time.Local).Format(layout)
+		drawText(img, face, chartMuted, x(t)-textWidth(face, label)/2, plot.Max.Y+22, label)
+	}
+
+	// Lines are drawn as steps, a total only changes when something is
+	// bought, from the lowest ranked up so the leader ends up on top.
+	for i := len(chart.Standings) - 1; i >= 0; i-- {
+		userID := chart.Standings[i].UserID
+		c, ok := colors[userID]
+		if !ok {
+			continue
+		}
+
+		last := chartPoint{from, 0}
+		for _, point := range points[userID] {
+			if point.at.Before(from) {
+				point.at = from
+			}
+			strokeLine(img, x(last.at), y(last.value), x(point.at), y(last.value), c)
+			strokeLine(img, x(point.at), y(last.value), x(point.at), y(point.value), c)
+			last = point
+		}
+		strokeLine(img, x(last.at), y(last.value), x(to), y(last.value), c)
+	}
+}
+
+type chartPoint struct {
+	at    time.Time
+	value float64
+}
+
+// strokeLine draws a horizontal or vertical line three pixels wide.
+func strokeLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+
+	fillRect(img, x0-1, y0-1, x1+2, y1+2, c)
+}
+
+func fillRect(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	draw.Draw(img, image.Rect(x0, y0, x1, y1), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// niceStep rounds a raw axis step up to 1, 2 or 5 times a power of ten.
+func niceStep(raw float64) float64 {
+	if raw <= 1 {
+		return 1
+	}
+
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, factor := range []float64{1, 2, 5, 10} {
+		if raw <= factor*magnitude {
+			return factor * magnitude
+		}
+	}
+
+	return 10 * magnitude
+}
+
+func formatScore(locale i18n.Locale, score float64, metric string) string {
+	if metric == models.LeaderboardSpend {
+		return i18n.T(locale, "leaderboard.spend", score)
+	}
+
+	return i18n.T(locale, "leaderboard.units", score)
+}
+
+func rankChange(locale i18n.Locale, standing models.TransactionLeaderboard) (string, color.RGBA) {
+	switch {
+	case standing.PreviousRank == 0:
+		return i18n.T(locale, "leaderboard.new"), chartUp
+	case standing.RankChange > 0:
+		return fmt.Sprintf("+%d", standing.RankChange), chartUp
+	case standing.RankChange < 0:
+		return fmt.Sprintf("−%d", -standing.RankChange), chartDown
+	default:
+		return "=", chartMuted
+	}
+}
+
+// userColor spreads n users evenly around the colour wheel, the same way
+// the kiosk's chart does.
+func userColor(i int, n int) color.RGBA {
+	h := float64(i) / float64(n)
+	s, l := 0.7, 0.5
+
+	q := l + s - l*s
+	p := 2*l - q
+	channel := func(t float64) uint8 {
+		t -= math.Floor(t)
+		switch {
+		case t < 1.0/6:
+			return uint8(math.Round((p + (q-p)*6*t) * 255))
+		case t < 1.0/2:
+			return uint8(math.Round(q * 255))
+		case t < 2.0/3:
+			return uint8(math.Round((p + (q-p)*(2.0/3-t)*6) * 255))
+		default:
+			return uint8(math.Round(p * 255))
+		}
+	}
+
+	return color.RGBA{channel(h + 1.0/3), channel(h), channel(h - 1.0/3), 255}
+}
+
+func loadFace(path string, size float64) (font.Face, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading font: %v", err)
+	}
+
+	parsed, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing font: %v", err)
+	}
+
+	return opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+func drawText(img *image.RGBA, face font.Face, c color.RGBA, x int, y int, text string) {
+	drawer := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(text)
+}
+
+func textWidth(face font.Face, text string) int {
+	return font.MeasureString(face, text).Round()
+}
+
+// truncateText shortens text with an ellipsis until it fits in width.
+func truncateText(face font.Face, text string, width int) string {
+	if textWidth(face, text) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && textWidth(face, string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "…"
+}
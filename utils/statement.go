@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"fmt"
+	"gostrecka/models"
+	"gostrecka/services/i18n"
+	"io"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// GenerateStatementPDF writes an itemised statement for a user to w in
+// locale.
+func GenerateStatementPDF(statement models.Statement, locale i18n.Locale, w io.Writer) error {
+	margin := 15.0
+	logoWidth := 60.0
+	columns := []struct {
+		title string
+		width float64
+		align string
+	}{
+		{i18n.T(locale, "statement.pdf_date"), 35, "L"},
+		{i18n.T(locale, "statement.pdf_kind"), 25, "L"},
+		{i18n.T(locale, "statement.pdf_product"), 55, "L"},
+		{i18n.T(locale, "statement.pdf_quantity"), 15, "R"},
+		{i18n.T(locale, "statement.pdf_unit_price"), 25, "R"},
+		{i18n.T(locale, "statement.pdf_amount"), 25, "R"},
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8Font("Roboto", "", "assets/Roboto-Regular.ttf")
+	pdf.AddUTF8Font("Roboto-Bold", "", "assets/Roboto-Bold.ttf")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin)
+	pdf.AddPage()
+
+	pdf.Image("assets/jamkat.png", margin, margin, logoWidth, logoWidth*(241.0/577.0), false, "", 0, "")
+	pdf.SetY(margin + logoWidth*(241.0/577.0) + 8)
+
+	pdf.SetFont("Roboto-Bold", "", 20)
+	pdf.CellFormat(0, 10, i18n.T(locale, "statement.pdf_title"), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Roboto", "", 11)
+	pdf.CellFormat(0, 6, statement.User.Name, "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, i18n.T(locale, "statement.pdf_period",
+		statement.From.Format("2006-01-02"),
+		statement.To.AddDate(0, 0, -1).Format("2006-01-02"),
+	), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont("Roboto-Bold", "", 10)
+	for _, column := range columns {
+		pdf.CellFormat(column.width, 7, column.title, "B", 0, column.align, false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Roboto", "", 10)
+	pdf.CellFormat(155, 7, i18n.T(locale, "statement.pdf_opening"), "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 7, formatAmount(locale, statement.OpeningBalance), "", 1, "R", false, 0, "")
+
+	for _, entry := range statement.Entries {
+		values := []string{
+			entry.Date.Local().Format("2006-01-02 15:04"),
+			i18n.T(locale, "statement.kind_"+entry.Kind),
+			entry.Description,
+			fmt.Sprintf("%d", entry.Quantity),
+			fmt.Sprintf("%.02f", entry.UnitPrice),
+			formatAmount(locale, entry.Amount),
+		}
+
+		// Payments have no product or unit price.
+		if entry.Kind == models.StatementPayment {
+			values[3], values[4] = "", ""
+		}
+
+		for i, column := range columns {
+			pdf.CellFormat(column.width, 6, values[i], "", 0, column.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	if len(statement.Entries) == 0 {
+		pdf.CellFormat(0, 6, i18n.T(locale, "statement.pdf_empty"), "", 1, "L", false, 0, "")
+	}
+
+	pdf.SetFont("Roboto-Bold", "", 10)
+	pdf.CellFormat(155, 8, i18n.T(locale, "statement.pdf_closing"), "T", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 8, formatAmount(locale, statement.ClosingBalance), "T", 1, "R", false, 0, "")
+
+	pdf.SetFont("Roboto", "", 9)
+	pdf.Ln(2)
+	pdf.CellFormat(0, 5, i18n.T(locale, "statement.pdf_note"), "", 1, "L", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+func formatAmount(locale i18n.Locale, amount float64) string {
+	return i18n.T(locale, "statement.pdf_money", amount)
+}
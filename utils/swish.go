@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+)
+
+const (
+	// swishMessageLength is the longest message Swish accepts.
+	swishMessageLength = 50
+
+	// swishEditableAmount lets the payer change the amount, so debts can
+	// be paid off in parts, while payee and message stay locked.
+	swishEditableAmount = 2
+)
+
+// SwishPayload builds a Swish payment request in the C-format read by the
+// Swish app: C<payee>;<amount>;<message>;<editable fields>.
+func SwishPayload(payee string, amount float64, message string) string {
+	payee = strings.NewReplacer(" ", "", "-", "").Replace(payee)
+
+	// The fields are separated by semicolons, so they cannot be part of
+	// the message.
+	message = strings.ReplaceAll(message, ";", " ")
+	if runes := []rune(message); len(runes) > swishMessageLength {
+		message = string(runes[:swishMessageLength])
+	}
+
+	return fmt.Sprintf("C%s;%.2f;%s;%d", payee, amount, message, swishEditableAmount)
+}
+
+// GenerateSwishQR returns a PNG QR code of size pixels for a Swish payment
+// request.
+func GenerateSwishQR(payee string, amount float64, message string, size int) ([]byte, error) {
+	code, err := qr.Encode(SwishPayload(payee, amount, message), qr.M, qr.Unicode)
+	if err != nil {
+		return nil, err
+	}
+
+	scaled, err := barcode.Scale(code, size, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertToEightBitPNG(scaled)
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSwishPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payee   string
+		amount  float64
+		message string
+		want    string
+	}{
+		{"plain", "1231234567", 42.5, "Strecka 123", "C1231234567;42.50;Strecka 123;2"},
+		{"payee separators", "123-123 45 67", 10, "x", "C1231234567;10.00;x;2"},
+		{"rounded amount", "1231234567", 9.999, "x", "C1231234567;10.00;x;2"},
+		{"semicolons", "1231234567", 1, "a;b;c", "C1231234567;1.00;a b c;2"},
+		{"empty message", "1231234567", 1, "", "C1231234567;1.00;;2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SwishPayload(tt.payee, tt.amount, tt.message); got != tt.want {
+				t.Errorf("SwishPayload() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSwishPayloadTruncatesMessage(t *testing.T) {
+	message := strings.Repeat("å", swishMessageLength+10)
+
+	got := SwishPayload("1231234567", 1, message)
+	want := "C1231234567;1.00;" + strings.Repeat("å", swishMessageLength) + ";2"
+	if got != want {
+		t.Errorf("SwishPayload() = %q, want %q", got, want)
+	}
+}